@@ -5,32 +5,156 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/killean-solvely/hsapi-gen/pkg/codegen"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
 )
 
 type Config struct {
-	Outfolder string `json:"outfolder"`
-	Schemas   []struct {
+	Outfolder     string   `json:"outfolder"`
+	Targets       []string `json:"targets"`
+	GoPackage     string   `json:"goPackage"`
+	ProtoPackage  string   `json:"protoPackage"`
+	TemplatesDir  string   `json:"templatesDir"`
+	Zod           bool     `json:"zod"`
+	EnumStyle     string   `json:"enumStyle"`
+	ShareEnums    bool     `json:"shareEnums"`
+	MergeStrategy string   `json:"mergeStrategy"`
+	TypeOverrides []struct {
+		Property string             `json:"property"`
+		Type     string             `json:"type"`
+		Import   *portal.TypeImport `json:"import"`
+		Portal   string             `json:"portal"`
+	} `json:"typeOverrides"`
+	Outputs []struct {
+		Template string `json:"template"`
+		Filename string `json:"filename"`
+		Scope    string `json:"scope"`
+	} `json:"outputs"`
+	Python struct {
+		Package string `json:"package"`
+		Client  bool   `json:"client"`
+	} `json:"python"`
+	Schemas []struct {
 		Name  string `json:"name"`
 		Token string `json:"token"`
 	} `json:"schemas"`
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "erd" {
+		runERD(os.Args[2:])
+		return
+	}
+
 	configPathPtr := flag.String("config", "", "Path to the configuration file")
 
 	flag.Parse()
 
-	if *configPathPtr == "" {
+	config := loadConfig(*configPathPtr)
+
+	fmt.Println("Starting code generation")
+
+	codegen := newCodegen(config)
+
+	err := codegen.GenerateCode(config.Outfolder)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Code generation complete")
+}
+
+// Exports the portal models in a format other than code
+func runExport(args []string) {
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	configPathPtr := exportFlags.String("config", "", "Path to the configuration file")
+	formatPtr := exportFlags.String("format", "", "Format to export the portal models as (jsonschema, openapi, sql, docs)")
+	fromPtr := exportFlags.String("from", "", "SQL snapshot to migrate from, used with -to")
+	toPtr := exportFlags.String("to", "", "SQL snapshot to migrate to, used with -from")
+
+	exportFlags.Parse(args)
+
+	if *formatPtr == "" {
+		fmt.Println("Format is required. Use -format to choose the export format.")
+		panic("Format is required")
+	}
+
+	config := loadConfig(*configPathPtr)
+
+	fmt.Println("Starting export")
+
+	gen := newCodegen(config)
+
+	// Diffing two snapshots only needs the files, not the portals
+	if *fromPtr != "" || *toPtr != "" {
+		if codegen.ExportFormat(*formatPtr) != codegen.ExportSQL || *fromPtr == "" || *toPtr == "" {
+			fmt.Println("Migrations need -format sql with both -from and -to.")
+			panic("Invalid migration flags")
+		}
+
+		err := gen.ExportSQLMigration(config.Outfolder, *fromPtr, *toPtr)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println("Export complete")
+		return
+	}
+
+	err := gen.Export(config.Outfolder, codegen.ExportFormat(*formatPtr))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Export complete")
+}
+
+// Draws the associations between the portal objects as entity-relationship diagrams
+func runERD(args []string) {
+	erdFlags := flag.NewFlagSet("erd", flag.ExitOnError)
+	configPathPtr := erdFlags.String("config", "", "Path to the configuration file")
+	customPtr := erdFlags.Bool("custom", false, "Only draw custom objects")
+	objectsPtr := erdFlags.String("objects", "", "Comma separated internal names of the objects to draw")
+
+	erdFlags.Parse(args)
+
+	config := loadConfig(*configPathPtr)
+
+	filter := codegen.ERDFilter{CustomOnly: *customPtr}
+	for _, name := range strings.Split(*objectsPtr, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Objects = append(filter.Objects, name)
+		}
+	}
+
+	fmt.Println("Starting diagram export")
+
+	gen := newCodegen(config)
+
+	err := gen.ExportERD(config.Outfolder, filter)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Diagram export complete")
+}
+
+// Loads the configuration file
+func loadConfig(configPath string) Config {
+	if configPath == "" {
 		fmt.Println(
 			"Config is required. Use -config to provide the path to the configuration file.",
 		)
 		panic("Config is required")
 	}
 
-	// Load the configuration file
-	data, err := os.ReadFile(*configPathPtr)
+	data, err := os.ReadFile(configPath)
 	if err != nil {
 		panic(err)
 	}
@@ -41,17 +165,51 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Println("Starting code generation")
+	return config
+}
 
-	codegen := codegen.NewCodegen()
-	for _, s := range config.Schemas {
-		codegen.AddPortal(s.Name, s.Token)
+// Creates a code generator with the portals and options from the configuration
+func newCodegen(config Config) *codegen.Codegen {
+	targets := []codegen.Target{}
+	for _, target := range config.Targets {
+		targets = append(targets, codegen.Target(target))
 	}
 
-	err = codegen.GenerateCode(config.Outfolder)
-	if err != nil {
-		panic(err)
+	outputs := []codegen.Output{}
+	for _, output := range config.Outputs {
+		outputs = append(outputs, codegen.Output{
+			Template: output.Template,
+			Filename: output.Filename,
+			Scope:    codegen.OutputScope(output.Scope),
+		})
 	}
 
-	fmt.Println("Code generation complete")
+	typeOverrides := []codegen.TypeOverride{}
+	for _, override := range config.TypeOverrides {
+		typeOverrides = append(typeOverrides, codegen.TypeOverride{
+			Property: override.Property,
+			Type:     override.Type,
+			Import:   override.Import,
+			Portal:   override.Portal,
+		})
+	}
+
+	gen := codegen.NewCodegen()
+	gen.SetTargets(targets...)
+	gen.SetGoPackage(config.GoPackage)
+	gen.SetProtoPackage(config.ProtoPackage)
+	gen.SetZod(config.Zod)
+	gen.SetEnumStyle(codegen.EnumStyle(config.EnumStyle))
+	gen.SetShareEnums(config.ShareEnums)
+	gen.SetMergeStrategy(codegen.MergeStrategy(config.MergeStrategy))
+	gen.SetTemplatesDir(config.TemplatesDir)
+	gen.SetOutputs(outputs...)
+	gen.SetTypeOverrides(typeOverrides...)
+	gen.SetPythonPackage(config.Python.Package)
+	gen.SetPythonClient(config.Python.Client)
+	for _, s := range config.Schemas {
+		gen.AddPortal(s.Name, s.Token)
+	}
+
+	return gen
 }
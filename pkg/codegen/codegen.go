@@ -1,24 +1,89 @@
 package codegen
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path"
+	"slices"
+	"strconv"
 	"sync"
 
 	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
 	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
 )
 
+// Target is a language the code can be generated for
+type Target string
+
+const (
+	TargetTypeScript Target = "typescript"
+	TargetGo         Target = "go"
+	TargetPython     Target = "python"
+	TargetGraphQL    Target = "graphql"
+	TargetProtobuf   Target = "protobuf"
+)
+
+// EnumStyle is how enumeration properties are declared in the generated TypeScript
+type EnumStyle string
+
+const (
+	// TypeScript enums
+	EnumStyleEnum EnumStyle = "enum"
+	// const objects with a string literal union type of the same name
+	EnumStyleConst EnumStyle = "const"
+)
+
+// MergeStrategy is how the objects of the portals are combined into the shared types
+type MergeStrategy string
+
+const (
+	// Only the objects and properties every portal has
+	MergeIntersection MergeStrategy = "intersection"
+	// The objects and properties of every portal, where the ones only some portals have are optional
+	MergeUnion MergeStrategy = "union"
+	// Like union, with object types and a client typed for each portal on top
+	MergePerPortal MergeStrategy = "per-portal"
+)
+
+// ExportFormat is a format the portal models can be exported as
+type ExportFormat string
+
+const (
+	ExportJSONSchema ExportFormat = "jsonschema"
+	ExportOpenAPI    ExportFormat = "openapi"
+	ExportSQL        ExportFormat = "sql"
+	ExportDocs       ExportFormat = "docs"
+)
+
 type Codegen struct {
 	PortalDefinitions []portal.PortalDefinition
 	logger            *log.Logger
+	targets           []Target
+	goPackage         string
+	protoPackage      string
+	pythonPackage     string
+	pythonClient      bool
+	zod               bool
+	templatesDir      string
+	outputs           []Output
+	transformers      []Transformer
+	typeOverrides     []TypeOverride
+	enumStyle         EnumStyle
+	shareEnums        bool
+	mergeStrategy     MergeStrategy
 }
 
 func NewCodegen() *Codegen {
 	return &Codegen{
 		PortalDefinitions: []portal.PortalDefinition{},
 		logger:            log.New(os.Stdout, "[CODEGEN] ", log.LstdFlags),
+		targets:           []Target{TargetTypeScript},
+		goPackage:         "hubspot",
+		protoPackage:      "hubspot",
+		enumStyle:         EnumStyleEnum,
+		mergeStrategy:     MergeIntersection,
+		pythonPackage:     "hubspot_portals",
 	}
 }
 
@@ -28,6 +93,20 @@ func (c *Codegen) SetLogger(logger *log.Logger) {
 	}
 }
 
+// Sets the languages to generate code for, defaults to TypeScript only
+func (c *Codegen) SetTargets(targets ...Target) {
+	if len(targets) > 0 {
+		c.targets = targets
+	}
+}
+
+// Sets the package name of the generated Go code, which is also the folder it is written to
+func (c *Codegen) SetGoPackage(name string) {
+	if name != "" {
+		c.goPackage = name
+	}
+}
+
 // Adds the portal to the list of portals to be processed
 func (c *Codegen) AddPortal(portalName, token string) {
 	c.PortalDefinitions = append(
@@ -37,6 +116,17 @@ func (c *Codegen) AddPortal(portalName, token string) {
 }
 
 func (c Codegen) GenerateCode(outfolder string) error {
+	switch c.mergeStrategy {
+	case MergeIntersection, MergeUnion, MergePerPortal:
+	default:
+		return fmt.Errorf("unknown merge strategy %q", c.mergeStrategy)
+	}
+
+	// resolvers.ts imports the object types from shared.ts
+	if slices.Contains(c.targets, TargetGraphQL) && !slices.Contains(c.targets, TargetTypeScript) {
+		return fmt.Errorf("the %q target needs the %q target", TargetGraphQL, TargetTypeScript)
+	}
+
 	err := c.loadPortals()
 	if err != nil {
 		return err
@@ -44,9 +134,35 @@ func (c Codegen) GenerateCode(outfolder string) error {
 
 	sharedPD := c.createSharedPortalDefinition()
 
+	err = c.runTransformers(sharedPD)
+	if err != nil {
+		return err
+	}
+
 	return c.generateFiles(outfolder, sharedPD)
 }
 
+// Exports the portal models in the given format instead of generating code
+func (c Codegen) Export(outfolder string, format ExportFormat) error {
+	err := c.loadPortals()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ExportJSONSchema:
+		return c.exportJSONSchema(path.Join(outfolder, "jsonschema"))
+	case ExportOpenAPI:
+		return c.exportOpenAPI(path.Join(outfolder, "openapi"))
+	case ExportSQL:
+		return c.exportSQL(path.Join(outfolder, "sql"))
+	case ExportDocs:
+		return c.exportDocs(path.Join(outfolder, "docs"))
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
 // Loads the portal definitions from the HubSpot API, or file if available
 func (c *Codegen) loadPortals() error {
 	var wg sync.WaitGroup
@@ -54,6 +170,8 @@ func (c *Codegen) loadPortals() error {
 	for i := range c.PortalDefinitions {
 		wg.Add(1)
 		go func(pd *portal.PortalDefinition) {
+			pd.SetTypeOverrides(c.typeOverridesForPortal(pd.PortalName))
+			pd.SetShareEnums(c.shareEnums)
 			err := pd.LoadPortalDefinition()
 			if err != nil {
 				c.logger.Printf("Error loading portal definition for %s: %s\n", pd.PortalName, err)
@@ -77,6 +195,11 @@ func (c Codegen) createSharedPortalDefinition() *portal.PortalDefinition {
 		return sharedPD
 	}
 
+	if c.mergeStrategy == MergeUnion || c.mergeStrategy == MergePerPortal {
+		c.unionPortalDefinitions(sharedPD)
+		return sharedPD
+	}
+
 	// Initialize the intersecting objects map
 	objectMap := make(map[string]portal.Object)
 
@@ -113,7 +236,11 @@ func (c Codegen) createSharedPortalDefinition() *portal.PortalDefinition {
 	sharedPD.Objects = intersectingObjects
 
 	// Intersect Enums across all portals
-	sharedPD.Enums = intersectEnumsAcrossPortals(c.PortalDefinitions)
+	sharedPD.Enums = keepReferencedEnums(
+		intersectEnumsAcrossPortals(c.PortalDefinitions),
+		sharedPD.Objects,
+		c.PortalDefinitions,
+	)
 
 	// Intersect ObjectNameToType across all portals
 	sharedPD.ObjectNameToType = intersectObjectNameToTypeAcrossPortals(c.PortalDefinitions)
@@ -121,6 +248,9 @@ func (c Codegen) createSharedPortalDefinition() *portal.PortalDefinition {
 	// Intersect AssociationTypes across all portals
 	sharedPD.AssociationTypes = intersectAssociationTypesAcrossPortals(c.PortalDefinitions)
 
+	// Intersect AssociatedObjects across all portals
+	sharedPD.AssociatedObjects = intersectAssociatedObjectsAcrossPortals(c.PortalDefinitions)
+
 	return sharedPD
 }
 
@@ -193,6 +323,31 @@ func intersectAssociationTypesAcrossPortals(
 	return assocTypeMap
 }
 
+// Helper function to intersect the associable object pairs across all portals
+func intersectAssociatedObjectsAcrossPortals(
+	portals []portal.PortalDefinition,
+) map[string][]string {
+	associatedMap := make(map[string][]string)
+
+	// Keep the pairs of the first portal that exist in every other portal
+	for fromName, toNames := range portals[0].AssociatedObjects {
+		for _, toName := range toNames {
+			found := true
+			for _, portalDef := range portals[1:] {
+				if !slices.Contains(portalDef.AssociatedObjects[fromName], toName) {
+					found = false
+					break
+				}
+			}
+			if found {
+				associatedMap[fromName] = append(associatedMap[fromName], toName)
+			}
+		}
+	}
+
+	return associatedMap
+}
+
 // Helper function to find intersecting properties across all portals for a given object
 func intersectPropertiesAcrossPortals(
 	objectName string,
@@ -212,13 +367,22 @@ func intersectPropertiesAcrossPortals(
 
 	// Compare properties with other portals
 	for _, portalDef := range portals[1:] {
-		for propName := range propertyMap {
+		for propName, sharedProp := range propertyMap {
 			found := false
 			for _, obj := range portalDef.Objects {
 				if obj.InternalName == objectName {
 					for _, prop := range obj.Properties {
 						if prop.Name == propName {
 							found = true
+							// A property can only be used as a lookup key if it is unique everywhere
+							if !prop.Unique && sharedProp.Unique {
+								sharedProp.Unique = false
+								propertyMap[propName] = sharedProp
+							}
+							if prop.TypeOverridden || sharedProp.TypeOverridden {
+								sharedProp = mergeOverriddenProperty(sharedProp, prop)
+								propertyMap[propName] = sharedProp
+							}
 							break
 						}
 					}
@@ -271,7 +435,75 @@ func intersectEnumsAcrossPortals(portals []portal.PortalDefinition) []portal.Enu
 	return intersectingEnums
 }
 
-// Generates the code for the portals
+// Sets the folder with templates that override the embedded templates of the same file name
+func (c *Codegen) SetTemplatesDir(dir string) {
+	c.templatesDir = dir
+}
+
+// Returns the renderer of the built-in templates, using the templates folder if one is set
+func (c Codegen) renderer() templates.Renderer {
+	return templates.NewRenderer(c.templatesDir)
+}
+
+// Sets how enumeration properties are declared in the generated TypeScript
+func (c *Codegen) SetEnumStyle(style EnumStyle) {
+	if style != "" {
+		c.enumStyle = style
+	}
+}
+
+// Sets how the objects of the portals are combined into the shared types, defaults to their intersection
+func (c *Codegen) SetMergeStrategy(strategy MergeStrategy) {
+	if strategy != "" {
+		c.mergeStrategy = strategy
+	}
+}
+
+// Sets whether enumeration properties with identical options share a single enum
+func (c *Codegen) SetShareEnums(enabled bool) {
+	c.shareEnums = enabled
+}
+
+// Sets the TypeScript types that replace the generated types of properties
+func (c *Codegen) SetTypeOverrides(overrides ...TypeOverride) {
+	c.typeOverrides = overrides
+}
+
+// Adds a transformer that runs on the portal models before code is generated
+func (c *Codegen) AddTransformer(transformer Transformer) {
+	c.transformers = append(c.transformers, transformer)
+}
+
+// Sets the extra files rendered from user defined templates
+func (c *Codegen) SetOutputs(outputs ...Output) {
+	c.outputs = outputs
+}
+
+// Sets the package name of the generated protobuf definitions, which is also the name of the .proto file
+func (c *Codegen) SetProtoPackage(name string) {
+	if name != "" {
+		c.protoPackage = name
+	}
+}
+
+// Sets the package name of the generated Python code, which is also the folder it is written to
+func (c *Codegen) SetPythonPackage(name string) {
+	if name != "" {
+		c.pythonPackage = name
+	}
+}
+
+// Enables generating a Python client that wraps hubspot-api-client
+func (c *Codegen) SetPythonClient(enabled bool) {
+	c.pythonClient = enabled
+}
+
+// Enables generating Zod schemas for the TypeScript objects and validating client responses with them
+func (c *Codegen) SetZod(enabled bool) {
+	c.zod = enabled
+}
+
+// Generates the code for the portals in every target language
 func (c Codegen) generateFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
 	// Check to see if the output folder exists
 	if _, err := os.Stat(outfolder); os.IsNotExist(err) {
@@ -282,6 +514,37 @@ func (c Codegen) generateFiles(outfolder string, sharedPD *portal.PortalDefiniti
 		}
 	}
 
+	for _, target := range c.targets {
+		var err error
+		switch target {
+		case TargetTypeScript:
+			err = c.generateTypeScriptFiles(outfolder, sharedPD)
+		case TargetGo:
+			err = c.generateGoFiles(path.Join(outfolder, c.goPackage), sharedPD)
+		case TargetPython:
+			err = c.generatePythonFiles(path.Join(outfolder, c.pythonPackage), sharedPD)
+		case TargetGraphQL:
+			err = c.generateGraphQLFiles(outfolder, sharedPD)
+		case TargetProtobuf:
+			err = c.generateProtoFiles(outfolder, sharedPD)
+		default:
+			err = fmt.Errorf("unknown target %q", target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.generateOutputs(outfolder, sharedPD)
+}
+
+// Generates the TypeScript code for the portals
+func (c Codegen) generateTypeScriptFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
+	err := c.validateTypeScriptIdentifiers(sharedPD)
+	if err != nil {
+		return err
+	}
+
 	// Generate the client code
 	c.logger.Println("Generating Client Code...")
 	clientCode, err := c.generateClientCode(sharedPD)
@@ -299,7 +562,7 @@ func (c Codegen) generateFiles(outfolder string, sharedPD *portal.PortalDefiniti
 	c.logger.Println("Generating Portal Code...")
 	for i := range c.PortalDefinitions {
 		c.logger.Printf("Processing portal %s...\n", c.PortalDefinitions[i].PortalName)
-		portalCode, err := c.generatePortalCode(&c.PortalDefinitions[i])
+		portalCode, err := c.generatePortalCode(&c.PortalDefinitions[i], sharedPD)
 		if err != nil {
 			return err
 		}
@@ -328,6 +591,23 @@ func (c Codegen) generateFiles(outfolder string, sharedPD *portal.PortalDefiniti
 		return err
 	}
 
+	if c.zod {
+		// Generate the Zod schemas for the shared types
+		c.logger.Println("Generating Zod Schemas...")
+		schemasCode, err := c.renderer().GenerateSchemas(templates.SchemasTemplateInput{
+			Objects: sharedPD.Objects,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Write the schemas to a file
+		err = os.WriteFile(path.Clean(outfolder+"/"+"schemas.ts"), []byte(schemasCode), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -337,11 +617,28 @@ func (c Codegen) generateClientCode(sharedPD *portal.PortalDefinition) (string,
 		portalNames[pd.PortalName] = pd.PortalName
 	}
 
-	fileData, err := templates.GenerateClient(templates.HubspotClientTemplateInput{
-		PortalNames:      portalNames,
-		ObjectNameToType: sharedPD.ObjectNameToType,
-		AssociationTypes: sharedPD.AssociationTypes,
-	})
+	input := templates.HubspotClientTemplateInput{
+		PortalNames:       portalNames,
+		ObjectNameToType:  sharedPD.ObjectNameToType,
+		AssociationTypes:  sharedPD.AssociationTypes,
+		AssociatedObjects: sharedPD.AssociatedObjects,
+		Zod:               c.zod,
+	}
+
+	// Every portal gets a client with its own objects and associations
+	if c.mergeStrategy == MergePerPortal {
+		input.PortalClients = map[string]templates.HubspotClientTemplateInput{}
+		for _, pd := range c.PortalDefinitions {
+			input.PortalClients[pd.PortalName] = templates.HubspotClientTemplateInput{
+				ObjectNameToType:  pd.ObjectNameToType,
+				AssociationTypes:  pd.AssociationTypes,
+				AssociatedObjects: pd.AssociatedObjects,
+				Zod:               c.zod,
+			}
+		}
+	}
+
+	fileData, err := c.renderer().GenerateClient(input)
 	if err != nil {
 		return "", err
 	}
@@ -349,17 +646,27 @@ func (c Codegen) generateClientCode(sharedPD *portal.PortalDefinition) (string,
 	return fileData, nil
 }
 
-func (c Codegen) generatePortalCode(p *portal.PortalDefinition) (string, error) {
+func (c Codegen) generatePortalCode(
+	p *portal.PortalDefinition,
+	sharedPD *portal.PortalDefinition,
+) (string, error) {
 	objectMap := map[string]string{}
 	for _, obj := range p.Objects {
 		objectMap[obj.InternalName] = obj.ID
 	}
 
-	fileData, err := templates.GeneratePortal(templates.PortalTemplateInput{
-		PortalName:       p.PortalName,
-		AssociationTypes: p.AssociationTypes,
-		Objects:          objectMap,
-	})
+	input := templates.PortalTemplateInput{
+		PortalName:        p.PortalName,
+		AssociationTypes:  p.AssociationTypes,
+		AssociatedObjects: p.AssociatedObjects,
+		Objects:           objectMap,
+	}
+	if c.mergeStrategy == MergePerPortal {
+		input.PerPortal = true
+		input.ObjectTypes = portalObjectTypes(p, sharedPD)
+	}
+
+	fileData, err := c.renderer().GeneratePortal(input)
 	if err != nil {
 		return "", err
 	}
@@ -368,10 +675,17 @@ func (c Codegen) generatePortalCode(p *portal.PortalDefinition) (string, error)
 }
 
 func (c Codegen) generateSharedCode(sharedPD *portal.PortalDefinition) (string, error) {
-	fileData, err := templates.GenerateShared(templates.SharedTemplateInput{
-		AssociationTypes: sharedPD.AssociationTypes,
-		Enums:            sharedPD.Enums,
-		Objects:          sharedPD.Objects,
+	if c.enumStyle != EnumStyleEnum && c.enumStyle != EnumStyleConst {
+		return "", fmt.Errorf("unknown enum style %q", c.enumStyle)
+	}
+
+	fileData, err := c.renderer().GenerateShared(templates.SharedTemplateInput{
+		AssociationTypes:  sharedPD.AssociationTypes,
+		AssociatedObjects: sharedPD.AssociatedObjects,
+		Enums:             sharedPD.Enums,
+		Objects:           sharedPD.Objects,
+		Imports:           typeScriptImports(sharedPD.Objects),
+		ConstEnums:        c.enumStyle == EnumStyleConst,
 	})
 	if err != nil {
 		return "", err
@@ -379,3 +693,13 @@ func (c Codegen) generateSharedCode(sharedPD *portal.PortalDefinition) (string,
 
 	return fileData, nil
 }
+
+// Appends a number to the identifier until it no longer collides with a used one
+func uniqueIdentifier(ident string, used map[string]bool) string {
+	unique := ident
+	for i := 2; used[unique]; i++ {
+		unique = ident + strconv.Itoa(i)
+	}
+	used[unique] = true
+	return unique
+}
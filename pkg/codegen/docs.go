@@ -0,0 +1,294 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/hs"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+)
+
+// Written in the cells of a portal that doesn't have the object, property or association
+const docsMissing = "—"
+
+// Writes a Markdown page per object and a single HTML page describing the objects of every portal
+func (c Codegen) exportDocs(outfolder string) error {
+	err := os.MkdirAll(outfolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	input := c.docsTemplateInput()
+
+	c.logger.Println("Exporting Markdown data dictionary...")
+	indexCode, err := c.renderer().GenerateDocsIndex(input)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(outfolder, "index.md"), []byte(indexCode), 0644)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range input.Objects {
+		objectCode, err := c.renderer().GenerateDocsObject(templates.DocsObjectTemplateInput{
+			Portals: input.Portals,
+			Object:  obj,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(path.Join(outfolder, obj.FileName), []byte(objectCode), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.logger.Println("Exporting HTML data dictionary...")
+	htmlCode, err := c.renderer().GenerateDocsHTML(input)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(outfolder, "index.html"), []byte(htmlCode), 0644)
+}
+
+// Lines up the objects, properties and associations of every portal, so they can be
+// compared side by side
+func (c Codegen) docsTemplateInput() templates.DocsTemplateInput {
+	input := templates.DocsTemplateInput{}
+
+	// Schemas of every portal, keyed by the internal object name
+	schemas := []map[string]hs.Schema{}
+	objectNames := map[string]bool{}
+	for _, pd := range c.PortalDefinitions {
+		input.Portals = append(input.Portals, pd.PortalName)
+
+		portalSchemas := map[string]hs.Schema{}
+		for _, schema := range pd.Schemas {
+			name := strings.ToLower(schema.Name)
+			portalSchemas[name] = schema
+			objectNames[name] = true
+		}
+		schemas = append(schemas, portalSchemas)
+	}
+
+	names := []string{}
+	for name := range objectNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		obj := templates.DocsObject{
+			Name:     name,
+			FileName: name + ".md",
+		}
+
+		propertyNames := map[string]bool{}
+		for i := range c.PortalDefinitions {
+			schema, ok := schemas[i][name]
+			if !ok {
+				obj.Portals = append(obj.Portals, docsCell(docsMissing))
+				continue
+			}
+			obj.Portals = append(obj.Portals, docsCell(schema.ObjectTypeID))
+
+			// The first portal with the object names it
+			if obj.Label == "" {
+				obj.Label = schema.Labels.Singular
+				obj.Description = schema.Description
+			}
+
+			for _, prop := range schema.Properties {
+				if !prop.Archived {
+					propertyNames[prop.Name] = true
+				}
+			}
+		}
+		if obj.Label == "" {
+			obj.Label = name
+		}
+
+		props := []string{}
+		for propName := range propertyNames {
+			props = append(props, propName)
+		}
+		sort.Strings(props)
+
+		for _, propName := range props {
+			obj.Properties = append(obj.Properties, c.docsProperty(schemas, name, propName))
+		}
+
+		obj.Associations = c.docsAssociations(name)
+
+		input.Objects = append(input.Objects, obj)
+	}
+
+	return input
+}
+
+// Describes a property in every portal, or once when it is the same everywhere
+func (c Codegen) docsProperty(schemas []map[string]hs.Schema, objectName, propName string) templates.DocsProperty {
+	attributeNames := []string{
+		"Label",
+		"Type",
+		"Group",
+		"Description",
+		"Options",
+		"Calculated",
+		"Hidden",
+		"Unique",
+	}
+
+	// Attribute values of every portal, in the order of the attribute names
+	portalValues := [][]templates.DocsCell{}
+	for i := range c.PortalDefinitions {
+		prop, ok := findDocsProperty(schemas[i][objectName], propName)
+		if !ok {
+			values := []templates.DocsCell{}
+			for range attributeNames {
+				values = append(values, docsCell(docsMissing))
+			}
+			portalValues = append(portalValues, values)
+			continue
+		}
+
+		propType := prop.Type
+		if prop.FieldType != "" {
+			propType = fmt.Sprintf("%s (%s)", prop.Type, prop.FieldType)
+		}
+
+		options := templates.DocsCell{}
+		for _, option := range prop.Options {
+			line := fmt.Sprintf("%s: %s", option.Value, option.Label)
+			if option.Hidden {
+				line += " (hidden)"
+			}
+			options.Lines = append(options.Lines, line)
+		}
+
+		portalValues = append(portalValues, []templates.DocsCell{
+			docsCell(prop.Label),
+			docsCell(propType),
+			docsCell(prop.GroupName),
+			docsCell(prop.Description),
+			options,
+			docsCell(docsYesNo(prop.Calculated)),
+			docsCell(docsYesNo(prop.Hidden)),
+			docsCell(docsYesNo(prop.HasUniqueValue)),
+		})
+	}
+
+	docsProp := templates.DocsProperty{Name: propName}
+
+	same := true
+	for _, values := range portalValues[1:] {
+		if !reflect.DeepEqual(values, portalValues[0]) {
+			same = false
+			break
+		}
+	}
+
+	if same {
+		docsProp.Columns = []string{"All portals"}
+		portalValues = portalValues[:1]
+	} else {
+		for _, pd := range c.PortalDefinitions {
+			docsProp.Columns = append(docsProp.Columns, pd.PortalName)
+		}
+	}
+
+	for i, attributeName := range attributeNames {
+		attribute := templates.DocsAttribute{Name: attributeName}
+		for _, values := range portalValues {
+			attribute.Cells = append(attribute.Cells, values[i])
+		}
+		docsProp.Attributes = append(docsProp.Attributes, attribute)
+	}
+
+	return docsProp
+}
+
+// Lists the association labels of an object, with the type ID of every portal defining it
+func (c Codegen) docsAssociations(objectName string) []templates.DocsAssociation {
+	type labelKey struct {
+		toName string
+		label  string
+	}
+
+	keys := []labelKey{}
+	seen := map[labelKey]bool{}
+	for _, pd := range c.PortalDefinitions {
+		for toName, labels := range pd.AssociationTypes[objectName] {
+			for label := range labels {
+				key := labelKey{toName: toName, label: label}
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].toName != keys[j].toName {
+			return keys[i].toName < keys[j].toName
+		}
+		return keys[i].label < keys[j].label
+	})
+
+	associations := []templates.DocsAssociation{}
+	for _, key := range keys {
+		association := templates.DocsAssociation{ToObject: key.toName, Key: key.label}
+		for _, pd := range c.PortalDefinitions {
+			assoc, ok := pd.AssociationTypes[objectName][key.toName][key.label]
+			if !ok {
+				association.Cells = append(association.Cells, docsCell(docsMissing))
+				continue
+			}
+			if association.Label == "" {
+				association.Label = assoc.Label
+			}
+			association.Cells = append(
+				association.Cells,
+				docsCell(fmt.Sprintf("%d (%s)", assoc.ID, assoc.Category)),
+			)
+		}
+		// Default associations have no label
+		if association.Label == "" {
+			association.Label = association.Key
+		}
+		associations = append(associations, association)
+	}
+
+	return associations
+}
+
+func findDocsProperty(schema hs.Schema, propName string) (hs.Property, bool) {
+	for _, prop := range schema.Properties {
+		if prop.Name == propName && !prop.Archived {
+			return prop, true
+		}
+	}
+	return hs.Property{}, false
+}
+
+func docsCell(value string) templates.DocsCell {
+	if value == "" {
+		return templates.DocsCell{}
+	}
+	return templates.DocsCell{Lines: []string{value}}
+}
+
+func docsYesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,225 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+)
+
+// Object type IDs of custom objects start with this prefix, standard objects use 0-
+const customObjectTypeIDPrefix = "2-"
+
+var invalidMermaidNameChars = regexp.MustCompile(`[^_0-9A-Za-z-]`)
+
+// ERDFilter narrows the objects drawn in the entity-relationship diagrams
+type ERDFilter struct {
+	// Only draw custom objects
+	CustomOnly bool
+	// Only draw these objects, by internal name. All objects are drawn when empty.
+	Objects []string
+}
+
+type erdNode struct {
+	Name         string
+	Label        string
+	ObjectTypeID string
+}
+
+type erdEdge struct {
+	From     string
+	To       string
+	Label    string
+	Category string
+}
+
+// Writes a Mermaid erDiagram and a Graphviz DOT file of the associations between the objects of every portal
+func (c Codegen) ExportERD(outfolder string, filter ERDFilter) error {
+	err := c.loadPortals()
+	if err != nil {
+		return err
+	}
+
+	outfolder = path.Join(outfolder, "erd")
+	err = os.MkdirAll(outfolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.PortalDefinitions {
+		pd := &c.PortalDefinitions[i]
+		c.logger.Printf("Exporting entity-relationship diagrams for portal %s...\n", pd.PortalName)
+
+		nodes, edges, err := erdGraph(pd, filter)
+		if err != nil {
+			return fmt.Errorf("portal %s: %w", pd.PortalName, err)
+		}
+
+		err = os.WriteFile(
+			path.Join(outfolder, pd.PortalName+".mmd"),
+			[]byte(mermaidERD(nodes, edges)),
+			0644,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(
+			path.Join(outfolder, pd.PortalName+".dot"),
+			[]byte(graphvizERD(pd.PortalName, nodes, edges)),
+			0644,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Collects the objects passing the filter, and the association types between them
+func erdGraph(pd *portal.PortalDefinition, filter ERDFilter) ([]erdNode, []erdEdge, error) {
+	wanted := map[string]bool{}
+	for _, name := range filter.Objects {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	nodes := []erdNode{}
+	included := map[string]bool{}
+	for _, schema := range pd.Schemas {
+		name := strings.ToLower(schema.Name)
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		if filter.CustomOnly && !strings.HasPrefix(schema.ObjectTypeID, customObjectTypeIDPrefix) {
+			continue
+		}
+
+		nodes = append(nodes, erdNode{
+			Name:         name,
+			Label:        schema.Labels.Singular,
+			ObjectTypeID: schema.ObjectTypeID,
+		})
+		included[name] = true
+	}
+
+	// Report objects that were asked for but don't exist, rather than silently drawing less
+	missing := []string{}
+	for name := range wanted {
+		if _, ok := pd.ObjectNameToType[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, nil, fmt.Errorf("unknown objects %s", strings.Join(missing, ", "))
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+
+	edges := []erdEdge{}
+	for fromName, toTypes := range pd.AssociationTypes {
+		if !included[fromName] {
+			continue
+		}
+		for toName, labels := range toTypes {
+			if !included[toName] {
+				continue
+			}
+			for name, assoc := range labels {
+				// Unlabeled associations are only known by their name
+				label := assoc.Label
+				if label == "" {
+					label = name
+				}
+				edges = append(edges, erdEdge{
+					From:     fromName,
+					To:       toName,
+					Label:    label,
+					Category: assoc.Category,
+				})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Label < edges[j].Label
+	})
+
+	return nodes, edges, nil
+}
+
+// Renders the graph as a Mermaid erDiagram, where every association can link any number of records
+func mermaidERD(nodes []erdNode, edges []erdEdge) string {
+	var b strings.Builder
+	b.WriteString("%% Code generated by hsapi-gen. DO NOT EDIT.\n")
+	b.WriteString("erDiagram\n")
+
+	for _, node := range nodes {
+		b.WriteString("    " + mermaidName(node.Name) + "\n")
+	}
+	for _, edge := range edges {
+		b.WriteString(fmt.Sprintf(
+			"    %s }o--o{ %s : \"%s\"\n",
+			mermaidName(edge.From),
+			mermaidName(edge.To),
+			mermaidLabel(edge.Label+" ("+edge.Category+")"),
+		))
+	}
+
+	return b.String()
+}
+
+// Renders the graph as a Graphviz digraph, with the object labels and type IDs on the nodes
+func graphvizERD(portalName string, nodes []erdNode, edges []erdEdge) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by hsapi-gen. DO NOT EDIT.\n")
+	b.WriteString("digraph " + graphvizString(portalName) + " {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range nodes {
+		b.WriteString(fmt.Sprintf(
+			"  %s [label=%s];\n",
+			graphvizString(node.Name),
+			graphvizString(node.Label+"\n"+node.ObjectTypeID),
+		))
+	}
+	for _, edge := range edges {
+		b.WriteString(fmt.Sprintf(
+			"  %s -> %s [label=%s];\n",
+			graphvizString(edge.From),
+			graphvizString(edge.To),
+			graphvizString(edge.Label+"\n"+edge.Category),
+		))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Replaces the characters Mermaid does not allow in entity names
+func mermaidName(name string) string {
+	return invalidMermaidNameChars.ReplaceAllString(name, "_")
+}
+
+// Replaces the quotes that would end a Mermaid relationship label
+func mermaidLabel(label string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(label), " "), `"`, "#quot;")
+}
+
+// Quotes the input as a DOT string, where newlines start a new centered line
+func graphvizString(input string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(input) + `"`
+}
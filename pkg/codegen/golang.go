@@ -0,0 +1,213 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Identifiers declared by the generated client, which objects and enums must not reuse
+var reservedGoIdentifiers = []string{
+	"APIError",
+	"Association",
+	"AssociationKey",
+	"Client",
+	"NewClient",
+	"Object",
+	"ObjectKey",
+	"Portal",
+	"Record",
+}
+
+// Generates the Go package for the portals
+func (c Codegen) generateGoFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
+	// Check to see if the output folder exists
+	if _, err := os.Stat(outfolder); os.IsNotExist(err) {
+		// Create the output folder
+		err := os.MkdirAll(outfolder, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the client code
+	c.logger.Println("Generating Go Client Code...")
+	portalIdents := map[string]string{}
+	for _, pd := range c.PortalDefinitions {
+		portalIdents[utils.ToGoIdentifier(pd.PortalName)] = pd.PortalName
+	}
+
+	clientCode, err := c.renderer().GenerateGoClient(templates.GoClientTemplateInput{
+		Package: c.goPackage,
+		Portals: portalIdents,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeGoFile(path.Join(outfolder, "client.go"), clientCode)
+	if err != nil {
+		return err
+	}
+
+	// Generate the code for the portals
+	c.logger.Println("Generating Go Portal Code...")
+	for i := range c.PortalDefinitions {
+		pd := &c.PortalDefinitions[i]
+		c.logger.Printf("Processing portal %s...\n", pd.PortalName)
+
+		objectMap := map[string]string{}
+		for _, obj := range pd.Objects {
+			objectMap[obj.InternalName] = obj.ID
+		}
+
+		portalCode, err := c.renderer().GenerateGoPortal(templates.GoPortalTemplateInput{
+			Package:          c.goPackage,
+			PortalName:       pd.PortalName,
+			PortalIdent:      utils.ToGoIdentifier(pd.PortalName),
+			Objects:          objectMap,
+			AssociationTypes: pd.AssociationTypes,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The fixed suffix keeps names like foo_test or eu_linux from turning the file into a test or
+		// a build constrained file
+		err = writeGoFile(path.Join(outfolder, pd.PortalName+"_portal.go"), portalCode)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the code for the shared types
+	c.logger.Println("Generating Go Shared Code...")
+	sharedCode, err := c.renderer().GenerateGoShared(c.goSharedTemplateInput(sharedPD))
+	if err != nil {
+		return err
+	}
+
+	return writeGoFile(path.Join(outfolder, "shared.go"), sharedCode)
+}
+
+// Converts the shared portal definition into Go identifiers and types
+func (c Codegen) goSharedTemplateInput(sharedPD *portal.PortalDefinition) templates.GoSharedTemplateInput {
+	usedNames := map[string]bool{}
+	for _, name := range reservedGoIdentifiers {
+		usedNames[name] = true
+	}
+
+	// Enums are sorted so the generated names are stable across runs
+	enums := append([]portal.Enum{}, sharedPD.Enums...)
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+
+	enumTypes := map[string]string{}
+	goEnums := []templates.GoEnum{}
+	for _, enum := range enums {
+		goEnum := templates.GoEnum{
+			Name: uniqueIdentifier(utils.ToGoIdentifier(enum.Name), usedNames),
+		}
+		enumTypes[enum.Name] = goEnum.Name
+
+		keys := []string{}
+		for key := range enum.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			goEnum.Values = append(goEnum.Values, templates.GoEnumValue{
+				Name: uniqueIdentifier(goEnum.Name+utils.ToGoIdentifier(key), usedNames),
+				// The values are escaped for TypeScript, so unescape them before quoting
+				Literal: strconv.Quote(strings.ReplaceAll(enum.Values[key], `\"`, `"`)),
+			})
+		}
+
+		goEnums = append(goEnums, goEnum)
+	}
+
+	objects := append([]portal.Object{}, sharedPD.Objects...)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].InternalName < objects[j].InternalName })
+
+	goObjects := []templates.GoObject{}
+	for _, obj := range objects {
+		goObject := templates.GoObject{
+			Name:         uniqueIdentifier(utils.ToGoIdentifier(obj.Name), usedNames),
+			InternalName: obj.InternalName,
+			KeyName:      uniqueIdentifier("Object"+utils.ToGoIdentifier(obj.InternalName), usedNames),
+		}
+		goObject.Comment = fmt.Sprintf(
+			"%s holds the properties of a %s record.",
+			goObject.Name,
+			obj.InternalName,
+		)
+		if description := sharedPD.ObjectNameToType[obj.InternalName].Description; description != "" {
+			goObject.Comment += " " + strings.Join(strings.Fields(description), " ")
+		}
+
+		props := append([]portal.Property{}, obj.Properties...)
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		// The ObjectKey method is declared on every struct
+		fieldNames := map[string]bool{"ObjectKey": true}
+		for _, prop := range props {
+			// HubSpot sends every property value as a string
+			fieldType, ok := enumTypes[prop.Type]
+			if !ok {
+				fieldType = "string"
+			}
+
+			goObject.Fields = append(goObject.Fields, templates.GoField{
+				Name:     uniqueIdentifier(utils.ToGoIdentifier(prop.Name), fieldNames),
+				JSONName: prop.Name,
+				Type:     fieldType,
+				Comment:  strings.Join(strings.Fields(prop.Comment), " "),
+			})
+		}
+
+		goObjects = append(goObjects, goObject)
+	}
+
+	labels := []string{}
+	for _, secondLayer := range sharedPD.AssociationTypes {
+		for _, labelMap := range secondLayer {
+			for label := range labelMap {
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+
+	associationKeys := []templates.GoAssociationKey{}
+	for _, label := range labels {
+		associationKeys = append(associationKeys, templates.GoAssociationKey{
+			Name:  uniqueIdentifier("Association"+utils.ToGoIdentifier(label), usedNames),
+			Value: label,
+		})
+	}
+
+	return templates.GoSharedTemplateInput{
+		Package:         c.goPackage,
+		Objects:         goObjects,
+		Enums:           goEnums,
+		AssociationKeys: associationKeys,
+	}
+}
+
+// Formats the generated Go source and writes it to the given file
+func writeGoFile(filename, code string) error {
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+
+	return os.WriteFile(filename, formatted, 0644)
+}
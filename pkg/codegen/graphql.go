@@ -0,0 +1,192 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+)
+
+var invalidGraphQLNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// Type names declared by the generated schema, which objects and enums must not reuse
+var reservedGraphQLNames = []string{
+	"Boolean",
+	"Float",
+	"ID",
+	"Int",
+	"Query",
+	"String",
+}
+
+// Generates the GraphQL schema for the shared types and the resolver interfaces for it
+func (c Codegen) generateGraphQLFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
+	input := graphQLTemplateInput(sharedPD)
+
+	c.logger.Println("Generating GraphQL Schema...")
+	schemaCode, err := c.renderer().GenerateGraphQLSchema(input)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(outfolder, "schema.graphql"), []byte(schemaCode), 0644)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Println("Generating GraphQL Resolvers...")
+	resolversCode, err := c.renderer().GenerateGraphQLResolvers(input)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(outfolder, "resolvers.ts"), []byte(resolversCode), 0644)
+}
+
+// Converts the shared portal definition into GraphQL names and types
+func graphQLTemplateInput(sharedPD *portal.PortalDefinition) templates.GraphQLTemplateInput {
+	usedNames := map[string]bool{}
+	for _, name := range reservedGraphQLNames {
+		usedNames[name] = true
+	}
+
+	// Enums are sorted so the generated names are stable across runs
+	enums := append([]portal.Enum{}, sharedPD.Enums...)
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+
+	enumTypes := map[string]string{}
+	gqlEnums := []templates.GraphQLEnum{}
+	for _, enum := range enums {
+		gqlEnum := templates.GraphQLEnum{
+			Name: uniqueIdentifier(graphQLName(enum.Name), usedNames),
+		}
+		enumTypes[enum.Name] = gqlEnum.Name
+
+		keys := []string{}
+		for key := range enum.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		valueNames := map[string]bool{}
+		for _, key := range keys {
+			gqlEnum.Values = append(gqlEnum.Values, templates.GraphQLEnumValue{
+				// Upper case names can never be one of the reserved true, false or null values
+				Name: uniqueIdentifier(strings.ToUpper(graphQLName(key)), valueNames),
+				// The values are escaped for TypeScript, so unescape them before quoting
+				Value: graphQLString(strings.ReplaceAll(enum.Values[key], `\"`, `"`)),
+			})
+		}
+
+		gqlEnums = append(gqlEnums, gqlEnum)
+	}
+
+	objects := append([]portal.Object{}, sharedPD.Objects...)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].InternalName < objects[j].InternalName })
+
+	typeNames := map[string]string{}
+	for _, obj := range objects {
+		typeNames[obj.InternalName] = uniqueIdentifier(graphQLName(obj.Name), usedNames)
+	}
+
+	gqlObjects := []templates.GraphQLObject{}
+	queryNames := map[string]bool{}
+	for _, obj := range objects {
+		gqlObject := templates.GraphQLObject{
+			Name:         typeNames[obj.InternalName],
+			InternalName: obj.InternalName,
+			QueryName:    uniqueIdentifier(graphQLName(obj.InternalName), queryNames),
+		}
+		if description := sharedPD.ObjectNameToType[obj.InternalName].Description; description != "" {
+			gqlObject.Description = graphQLString(description)
+		}
+
+		props := append([]portal.Property{}, obj.Properties...)
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		fieldNames := map[string]bool{"id": true}
+		for _, prop := range props {
+			fieldType, ok := enumTypes[prop.Type]
+			if !ok {
+				fieldType = "String"
+			}
+
+			field := templates.GraphQLField{
+				Name: uniqueIdentifier(graphQLName(prop.Name), fieldNames),
+				Type: fieldType,
+			}
+			if field.Name != prop.Name {
+				field.Property = prop.Name
+			}
+			if prop.Comment != "" {
+				field.Description = graphQLString(prop.Comment)
+			}
+
+			gqlObject.Fields = append(gqlObject.Fields, field)
+		}
+
+		// Each association label becomes a list of the associated records
+		toNames := []string{}
+		for toName := range sharedPD.AssociationTypes[obj.InternalName] {
+			toNames = append(toNames, toName)
+		}
+		sort.Strings(toNames)
+
+		for _, toName := range toNames {
+			toTypeName, ok := typeNames[toName]
+			if !ok {
+				continue
+			}
+
+			labels := []string{}
+			for label := range sharedPD.AssociationTypes[obj.InternalName][toName] {
+				labels = append(labels, label)
+			}
+			sort.Strings(labels)
+
+			for _, label := range labels {
+				gqlObject.Fields = append(gqlObject.Fields, templates.GraphQLField{
+					Name:           uniqueIdentifier(graphQLName(label), fieldNames),
+					Type:           "[" + toTypeName + "!]!",
+					AssociatedType: toTypeName,
+				})
+			}
+		}
+
+		gqlObjects = append(gqlObjects, gqlObject)
+	}
+
+	return templates.GraphQLTemplateInput{
+		Objects: gqlObjects,
+		Enums:   gqlEnums,
+	}
+}
+
+// Replaces the characters GraphQL does not allow in names
+func graphQLName(input string) string {
+	name := invalidGraphQLNameChars.ReplaceAllString(input, "_")
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	// Names starting with two underscores are reserved for introspection
+	if strings.HasPrefix(name, "__") {
+		name = "x" + name
+	}
+	return name
+}
+
+// Quotes the input as a GraphQL string, whose escapes are the same as JSON's
+func graphQLString(input string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(strings.Join(strings.Fields(input), " "))
+	return strings.TrimSuffix(buf.String(), "\n")
+}
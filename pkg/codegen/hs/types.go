@@ -75,5 +75,8 @@ type Option struct {
 }
 
 type ModificationMetadata struct {
-	// fields for modification metadata
+	Archivable         bool `json:"archivable"`         // Whether the property can be archived
+	ReadOnlyDefinition bool `json:"readOnlyDefinition"` // Whether the property definition can be changed
+	ReadOnlyOptions    bool `json:"readOnlyOptions"`    // Whether the property options can be changed
+	ReadOnlyValue      bool `json:"readOnlyValue"`      // Whether the property value can be set
 }
@@ -0,0 +1,185 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/hs"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is the subset of a JSON Schema document used to describe HubSpot objects
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        any                    `json:"type,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+	Const       *string                `json:"const,omitempty"`
+	Enum        []any                  `json:"enum,omitempty"`
+	OneOf       []*jsonSchema          `json:"oneOf,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	ReadOnly    bool                   `json:"readOnly,omitempty"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
+
+	// HubSpot specific keywords that JSON Schema has no equivalent for
+	ObjectTypeID string `json:"x-hubspot-object-type-id,omitempty"`
+	Unique       bool   `json:"x-hubspot-unique,omitempty"`
+	Calculated   bool   `json:"x-hubspot-calculated,omitempty"`
+	FieldType    string `json:"x-hubspot-field-type,omitempty"`
+	GroupName    string `json:"x-hubspot-group,omitempty"`
+	// Options of a checkbox enumeration, which can't be a oneOf as the value holds several of them
+	Options []*jsonSchema `json:"x-hubspot-options,omitempty"`
+}
+
+// Writes a JSON Schema document for every object of every portal
+func (c Codegen) exportJSONSchema(outfolder string) error {
+	for _, pd := range c.PortalDefinitions {
+		c.logger.Printf("Exporting JSON Schemas for portal %s...\n", pd.PortalName)
+
+		portalFolder := path.Join(outfolder, pd.PortalName)
+		err := os.MkdirAll(portalFolder, 0755)
+		if err != nil {
+			return err
+		}
+
+		for _, schema := range pd.Schemas {
+			doc := jsonSchemaForObject(schema, false)
+			doc.Schema = jsonSchemaDialect
+
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			err = os.WriteFile(
+				path.Join(portalFolder, strings.ToLower(schema.Name)+".schema.json"),
+				append(data, '\n'),
+				0644,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Describes an object and all of its non archived properties
+func jsonSchemaForObject(schema hs.Schema, wireFormat bool) *jsonSchema {
+	doc := &jsonSchema{
+		Title:        schema.Labels.Singular,
+		Description:  schema.Description,
+		Type:         "object",
+		Properties:   map[string]*jsonSchema{},
+		ObjectTypeID: schema.ObjectTypeID,
+	}
+
+	for _, prop := range schema.Properties {
+		if prop.Archived {
+			continue
+		}
+		doc.Properties[prop.Name] = jsonSchemaForProperty(prop, wireFormat)
+	}
+
+	return doc
+}
+
+// Describes a single property, using either the logical type of the value or,
+// in wire format, the nullable string HubSpot sends
+func jsonSchemaForProperty(prop hs.Property, wireFormat bool) *jsonSchema {
+	propSchema := &jsonSchema{
+		Title:       prop.Label,
+		Description: prop.Description,
+		ReadOnly:    prop.Calculated || prop.ModificationMetadata.ReadOnlyValue,
+		Unique:      prop.HasUniqueValue,
+		Calculated:  prop.Calculated,
+		FieldType:   prop.FieldType,
+		GroupName:   prop.GroupName,
+	}
+
+	switch prop.Type {
+	case "number":
+		propSchema.Type = "number"
+	case "bool":
+		propSchema.Type = "boolean"
+	case "date":
+		propSchema.Type = "string"
+		propSchema.Format = "date"
+	case "datetime":
+		propSchema.Type = "string"
+		propSchema.Format = "date-time"
+	case "enumeration":
+		propSchema.Type = "string"
+		options := []*jsonSchema{}
+		values := []string{}
+		for _, option := range prop.Options {
+			value := option.Value
+			options = append(options, &jsonSchema{
+				Const:       &value,
+				Title:       option.Label,
+				Description: option.Description,
+				Deprecated:  option.Hidden,
+			})
+			values = append(values, value)
+		}
+
+		// Checkbox enumerations hold any of the option values separated by ;
+		if prop.FieldType == "checkbox" {
+			propSchema.Options = options
+			propSchema.Pattern = multiValuePattern(values, wireFormat)
+		} else {
+			propSchema.OneOf = options
+		}
+	default:
+		propSchema.Type = "string"
+	}
+
+	if wireFormat {
+		switch propSchema.Type {
+		case "number":
+			propSchema.Format = "decimal"
+		case "boolean":
+			propSchema.Enum = []any{"true", "false", "", nil}
+		}
+		// The value constraints have to allow the null the type allows, and the empty string
+		// HubSpot sends for a cleared enumeration
+		if len(propSchema.OneOf) > 0 {
+			empty := ""
+			propSchema.OneOf = append(
+				propSchema.OneOf,
+				&jsonSchema{Const: &empty},
+				&jsonSchema{Type: "null"},
+			)
+		}
+		propSchema.Type = []string{"string", "null"}
+	}
+
+	return propSchema
+}
+
+// Matches one or more of the values separated by ;, or also an empty string when allowEmpty is set
+func multiValuePattern(values []string, allowEmpty bool) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, regexp.QuoteMeta(value))
+	}
+	value := "(?:" + strings.Join(quoted, "|") + ")"
+
+	pattern := value + "(?:;" + value + ")*"
+	if allowEmpty {
+		pattern = "(?:" + pattern + ")?"
+	}
+
+	return "^" + pattern + "$"
+}
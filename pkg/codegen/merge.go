@@ -0,0 +1,226 @@
+package codegen
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+)
+
+// Combines every object, property, enum and association of the portals, in the order they first
+// appear. Objects and properties that only some portals have list those portals.
+func (c Codegen) unionPortalDefinitions(sharedPD *portal.PortalDefinition) {
+	portals := c.PortalDefinitions
+
+	sharedPD.Objects = unionObjectsAcrossPortals(portals)
+	sharedPD.Enums = c.unionEnumsAcrossPortals(portals)
+	sharedPD.ObjectNameToType = map[string]portal.SchemaData{}
+	sharedPD.AssociationTypes = map[string]map[string]map[string]portal.Association{}
+	sharedPD.AssociatedObjects = map[string][]string{}
+
+	for _, portalDef := range portals {
+		for name, schemaData := range portalDef.ObjectNameToType {
+			if _, exists := sharedPD.ObjectNameToType[name]; !exists {
+				sharedPD.ObjectNameToType[name] = schemaData
+			}
+		}
+
+		for primaryType, subMap := range portalDef.AssociationTypes {
+			if sharedPD.AssociationTypes[primaryType] == nil {
+				sharedPD.AssociationTypes[primaryType] = map[string]map[string]portal.Association{}
+			}
+			for secondaryType, innerMap := range subMap {
+				if sharedPD.AssociationTypes[primaryType][secondaryType] == nil {
+					sharedPD.AssociationTypes[primaryType][secondaryType] = map[string]portal.Association{}
+				}
+				for assocName, association := range innerMap {
+					if _, exists := sharedPD.AssociationTypes[primaryType][secondaryType][assocName]; !exists {
+						sharedPD.AssociationTypes[primaryType][secondaryType][assocName] = association
+					}
+				}
+			}
+		}
+
+		for fromName, toNames := range portalDef.AssociatedObjects {
+			for _, toName := range toNames {
+				if !slices.Contains(sharedPD.AssociatedObjects[fromName], toName) {
+					sharedPD.AssociatedObjects[fromName] = append(sharedPD.AssociatedObjects[fromName], toName)
+				}
+			}
+		}
+	}
+}
+
+// Helper function to combine the objects of all portals, along with all of their properties
+func unionObjectsAcrossPortals(portals []portal.PortalDefinition) []portal.Object {
+	objects := []portal.Object{}
+	objectPortals := map[string][]string{}
+
+	for _, portalDef := range portals {
+		for _, obj := range portalDef.Objects {
+			if _, exists := objectPortals[obj.InternalName]; !exists {
+				obj.Properties = unionPropertiesAcrossPortals(obj.InternalName, portals)
+				objects = append(objects, obj)
+			}
+			objectPortals[obj.InternalName] = append(objectPortals[obj.InternalName], portalDef.PortalName)
+		}
+	}
+
+	for i := range objects {
+		if len(objectPortals[objects[i].InternalName]) < len(portals) {
+			objects[i].Portals = objectPortals[objects[i].InternalName]
+		}
+	}
+
+	return objects
+}
+
+// Helper function to combine the properties of an object across the portals that have it. A property
+// that is missing from one of them can't be used as a lookup key, as the lookup would fail there.
+func unionPropertiesAcrossPortals(
+	objectName string,
+	portals []portal.PortalDefinition,
+) []portal.Property {
+	props := []portal.Property{}
+	index := map[string]int{}
+	propPortals := map[string][]string{}
+	mixedTypes := map[string]bool{}
+	objectPortals := 0
+
+	for _, portalDef := range portals {
+		for _, obj := range portalDef.Objects {
+			if obj.InternalName != objectName {
+				continue
+			}
+			objectPortals++
+
+			for _, prop := range obj.Properties {
+				i, exists := index[prop.Name]
+				if !exists {
+					index[prop.Name] = len(props)
+					props = append(props, prop)
+				} else {
+					sharedProp := props[i]
+					if !prop.Unique {
+						sharedProp.Unique = false
+					}
+					if prop.TypeOverridden || sharedProp.TypeOverridden {
+						sharedProp = mergeOverriddenProperty(sharedProp, prop)
+					} else if prop.Type != sharedProp.Type || prop.HubspotType != sharedProp.HubspotType {
+						mixedTypes[prop.Name] = true
+					}
+					// Checkbox values also accept the single values of the other field types
+					if prop.FieldType == "checkbox" {
+						sharedProp.FieldType = prop.FieldType
+					}
+					props[i] = sharedProp
+				}
+				propPortals[prop.Name] = append(propPortals[prop.Name], portalDef.PortalName)
+			}
+			break
+		}
+	}
+
+	// Properties of an object only some portals have are only missing where the object is
+	for i := range props {
+		if mixedTypes[props[i].Name] && !props[i].TypeOverridden {
+			props[i] = stringTypedProperty(props[i])
+		}
+		if len(propPortals[props[i].Name]) < objectPortals {
+			props[i].Portals = propPortals[props[i].Name]
+			props[i].Unique = false
+		}
+	}
+
+	return props
+}
+
+// Helper function to type a property whose type differs between the portals as the string HubSpot
+// sends for every type, noting why in its comment
+func stringTypedProperty(prop portal.Property) portal.Property {
+	prop.Type = "string"
+	prop.HubspotType = "string"
+	prop.Comment = strings.TrimSpace(
+		prop.Comment + " Typed as a string, as the portals have different types for it.",
+	)
+	return prop
+}
+
+// Helper function to combine the enums of all portals, where enums of the same name get the
+// options of every portal, matched by value
+func (c Codegen) unionEnumsAcrossPortals(portals []portal.PortalDefinition) []portal.Enum {
+	names := []string{}
+	enumsByName := map[string][]portal.Enum{}
+	for _, portalDef := range portals {
+		for _, enum := range portalDef.Enums {
+			if _, exists := enumsByName[enum.Name]; !exists {
+				names = append(names, enum.Name)
+			}
+			enumsByName[enum.Name] = append(enumsByName[enum.Name], enum)
+		}
+	}
+
+	enums := []portal.Enum{}
+	for _, name := range names {
+		enum, warnings := portal.MergeEnums(name, enumsByName[name])
+		for _, warning := range warnings {
+			c.logger.Println("[shared] " + warning)
+		}
+		enums = append(enums, enum)
+	}
+
+	return enums
+}
+
+// Lists the shared objects the portal has, with the properties it has of them, for the object
+// types of the portal. Everything is sorted by name, so the types don't change with the order
+// HubSpot returns them in.
+func portalObjectTypes(
+	p *portal.PortalDefinition,
+	sharedPD *portal.PortalDefinition,
+) []templates.PortalObjectType {
+	sharedObjects := map[string]portal.Object{}
+	for _, obj := range sharedPD.Objects {
+		sharedObjects[obj.InternalName] = obj
+	}
+
+	objectTypes := []templates.PortalObjectType{}
+	for _, obj := range p.Objects {
+		sharedObj, ok := sharedObjects[obj.InternalName]
+		if !ok {
+			continue
+		}
+
+		sharedProps := map[string]bool{}
+		for _, prop := range sharedObj.Properties {
+			sharedProps[prop.Name] = true
+		}
+
+		objectType := templates.PortalObjectType{
+			InternalName:     obj.InternalName,
+			Interface:        sharedObj.Name,
+			Properties:       []string{},
+			UniqueProperties: []string{},
+		}
+		for _, prop := range obj.Properties {
+			if !sharedProps[prop.Name] {
+				continue
+			}
+			objectType.Properties = append(objectType.Properties, prop.Name)
+			if prop.Unique {
+				objectType.UniqueProperties = append(objectType.UniqueProperties, prop.Name)
+			}
+		}
+
+		sort.Strings(objectType.Properties)
+		sort.Strings(objectType.UniqueProperties)
+		objectTypes = append(objectTypes, objectType)
+	}
+	sort.Slice(objectTypes, func(i, j int) bool {
+		return objectTypes[i].InternalName < objectTypes[j].InternalName
+	})
+
+	return objectTypes
+}
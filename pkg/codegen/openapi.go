@@ -0,0 +1,335 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Writes an OpenAPI document for the object, batch and association endpoints of every portal
+func (c Codegen) exportOpenAPI(outfolder string) error {
+	err := os.MkdirAll(outfolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.PortalDefinitions {
+		pd := &c.PortalDefinitions[i]
+		c.logger.Printf("Exporting OpenAPI document for portal %s...\n", pd.PortalName)
+
+		data, err := json.MarshalIndent(openAPIDocument(pd), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(
+			path.Join(outfolder, pd.PortalName+".openapi.json"),
+			append(data, '\n'),
+			0644,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Builds the OpenAPI document of a portal, with the object properties typed from its schemas
+func openAPIDocument(pd *portal.PortalDefinition) map[string]any {
+	schemas := map[string]any{
+		"BatchReadInput": map[string]any{
+			"type":     "object",
+			"required": []string{"inputs"},
+			"properties": map[string]any{
+				"idProperty": map[string]any{"type": "string"},
+				"inputs": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type":       "object",
+						"required":   []string{"id"},
+						"properties": map[string]any{"id": map[string]any{"type": "string"}},
+					},
+				},
+				"properties": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+	paths := map[string]any{}
+
+	for _, schema := range pd.Schemas {
+		lowerSchemaName := strings.ToLower(schema.Name)
+		name := utils.ConvertSchemaNameToInterfaceName(schema.Name)
+
+		schemas[name+"Properties"] = jsonSchemaForObject(schema, true)
+		schemas[name] = map[string]any{
+			"type":     "object",
+			"required": []string{"id", "properties"},
+			"properties": map[string]any{
+				"id":         map[string]any{"type": "string"},
+				"properties": openAPIRef(name + "Properties"),
+				"createdAt":  map[string]any{"type": "string", "format": "date-time"},
+				"updatedAt":  map[string]any{"type": "string", "format": "date-time"},
+				"archived":   map[string]any{"type": "boolean"},
+			},
+		}
+		schemas[name+"Input"] = map[string]any{
+			"type":       "object",
+			"required":   []string{"properties"},
+			"properties": map[string]any{"properties": openAPIRef(name + "Properties")},
+		}
+		schemas[name+"BatchUpdateInput"] = map[string]any{
+			"type":     "object",
+			"required": []string{"id", "properties"},
+			"properties": map[string]any{
+				"id":         map[string]any{"type": "string"},
+				"idProperty": map[string]any{"type": "string"},
+				"properties": openAPIRef(name + "Properties"),
+			},
+		}
+		schemas[name+"BatchResponse"] = map[string]any{
+			"type":     "object",
+			"required": []string{"results"},
+			"properties": map[string]any{
+				"status":  map[string]any{"type": "string"},
+				"results": map[string]any{"type": "array", "items": openAPIRef(name)},
+			},
+		}
+
+		objectPath := "/crm/v3/objects/" + schema.ObjectTypeID
+		tags := []string{name}
+
+		paths[objectPath] = map[string]any{
+			"post": map[string]any{
+				"operationId": "create" + name,
+				"tags":        tags,
+				"requestBody": openAPIJSONBody(openAPIRef(name + "Input")),
+				"responses":   openAPIResponses("201", openAPIRef(name)),
+			},
+		}
+		paths[objectPath+"/{objectId}"] = map[string]any{
+			"parameters": []any{
+				openAPIPathParam("objectId"),
+			},
+			"get": map[string]any{
+				"operationId": "get" + name,
+				"tags":        tags,
+				"parameters": []any{
+					map[string]any{
+						"name":     "properties",
+						"in":       "query",
+						"style":    "form",
+						"explode":  false,
+						"schema":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						"required": false,
+					},
+					map[string]any{
+						"name":     "idProperty",
+						"in":       "query",
+						"schema":   map[string]any{"type": "string"},
+						"required": false,
+					},
+				},
+				"responses": openAPIResponses("200", openAPIRef(name)),
+			},
+			"patch": map[string]any{
+				"operationId": "update" + name,
+				"tags":        tags,
+				"requestBody": openAPIJSONBody(openAPIRef(name + "Input")),
+				"responses":   openAPIResponses("200", openAPIRef(name)),
+			},
+		}
+		paths[objectPath+"/batch/read"] = map[string]any{
+			"post": map[string]any{
+				"operationId": "batchRead" + name,
+				"tags":        tags,
+				"requestBody": openAPIJSONBody(openAPIRef("BatchReadInput")),
+				"responses":   openAPIResponses("200", openAPIRef(name+"BatchResponse")),
+			},
+		}
+		paths[objectPath+"/batch/create"] = map[string]any{
+			"post": map[string]any{
+				"operationId": "batchCreate" + name,
+				"tags":        tags,
+				"requestBody": openAPIJSONBody(openAPIBatchInput(openAPIRef(name + "Input"))),
+				"responses":   openAPIResponses("201", openAPIRef(name+"BatchResponse")),
+			},
+		}
+		paths[objectPath+"/batch/update"] = map[string]any{
+			"post": map[string]any{
+				"operationId": "batchUpdate" + name,
+				"tags":        tags,
+				"requestBody": openAPIJSONBody(openAPIBatchInput(openAPIRef(name + "BatchUpdateInput"))),
+				"responses":   openAPIResponses("200", openAPIRef(name+"BatchResponse")),
+			},
+		}
+
+		// Sort the associated objects so the operation IDs are stable across runs
+		toNames := []string{}
+		for toName := range pd.AssociationTypes[lowerSchemaName] {
+			toNames = append(toNames, toName)
+		}
+		sort.Strings(toNames)
+
+		for _, toName := range toNames {
+			labels := pd.AssociationTypes[lowerSchemaName][toName]
+			toSchemaData, ok := pd.ObjectNameToType[toName]
+			if !ok || len(labels) == 0 {
+				continue
+			}
+
+			pairName := name + "To" + toSchemaData.InterfaceName
+			schemas[pairName+"AssociationSpec"] = openAPIAssociationSpec(labels)
+
+			paths[fmt.Sprintf(
+				"/crm/v4/objects/%s/{objectId}/associations/%s/{toObjectId}",
+				schema.ObjectTypeID,
+				toSchemaData.ObjectID,
+			)] = map[string]any{
+				"parameters": []any{
+					openAPIPathParam("objectId"),
+					openAPIPathParam("toObjectId"),
+				},
+				"put": map[string]any{
+					"operationId": "associate" + pairName,
+					"tags":        tags,
+					"requestBody": openAPIJSONBody(map[string]any{
+						"type":  "array",
+						"items": openAPIRef(pairName + "AssociationSpec"),
+					}),
+					"responses": openAPIResponses("200", map[string]any{"type": "object"}),
+				},
+			}
+			paths[fmt.Sprintf(
+				"/crm/v4/associations/%s/%s/batch/create",
+				schema.ObjectTypeID,
+				toSchemaData.ObjectID,
+			)] = map[string]any{
+				"post": map[string]any{
+					"operationId": "batchAssociate" + pairName,
+					"tags":        tags,
+					"requestBody": openAPIJSONBody(openAPIBatchInput(map[string]any{
+						"type":     "object",
+						"required": []string{"from", "to", "types"},
+						"properties": map[string]any{
+							"from": openAPIRef("AssociationObjectID"),
+							"to":   openAPIRef("AssociationObjectID"),
+							"types": map[string]any{
+								"type":  "array",
+								"items": openAPIRef(pairName + "AssociationSpec"),
+							},
+						},
+					})),
+					"responses": openAPIResponses("201", map[string]any{"type": "object"}),
+				},
+			}
+		}
+	}
+
+	schemas["AssociationObjectID"] = map[string]any{
+		"type":       "object",
+		"required":   []string{"id"},
+		"properties": map[string]any{"id": map[string]any{"type": "string"}},
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   fmt.Sprintf("HubSpot CRM (%s)", pd.PortalName),
+			"version": "3",
+		},
+		"servers": []any{
+			map[string]any{"url": "https://api.hubapi.com"},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []string{}},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// Restricts an association spec to the association types defined between two objects
+func openAPIAssociationSpec(labels map[string]portal.Association) map[string]any {
+	labelNames := []string{}
+	for label := range labels {
+		labelNames = append(labelNames, label)
+	}
+	sort.Strings(labelNames)
+
+	oneOf := []any{}
+	for _, label := range labelNames {
+		assoc := labels[label]
+		oneOf = append(oneOf, map[string]any{
+			"title": label,
+			"type":  "object",
+			"properties": map[string]any{
+				"associationCategory": map[string]any{"const": assoc.Category},
+				"associationTypeId":   map[string]any{"const": assoc.ID},
+			},
+		})
+	}
+
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"associationCategory", "associationTypeId"},
+		"oneOf":    oneOf,
+	}
+}
+
+func openAPIRef(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func openAPIPathParam(name string) map[string]any {
+	return map[string]any{
+		"name":     name,
+		"in":       "path",
+		"required": true,
+		"schema":   map[string]any{"type": "string"},
+	}
+}
+
+func openAPIJSONBody(schema any) map[string]any {
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schema},
+		},
+	}
+}
+
+func openAPIBatchInput(item any) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"inputs"},
+		"properties": map[string]any{
+			"inputs": map[string]any{"type": "array", "items": item},
+		},
+	}
+}
+
+func openAPIResponses(status string, schema any) map[string]any {
+	return map[string]any{
+		status: map[string]any{
+			"description": "Successful response",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schema},
+			},
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+)
+
+// Placeholder in output file names that is replaced with the portal name
+const outputPortalPlaceholder = "{{portal}}"
+
+// OutputScope is whether an extra output is rendered for every portal or once
+type OutputScope string
+
+const (
+	OutputScopePortal OutputScope = "portal"
+	OutputScopeShared OutputScope = "shared"
+)
+
+// Output is an extra file rendered from a user defined template
+type Output struct {
+	// Path of the template file
+	Template string
+	// Name of the generated file inside the output folder. When rendered per portal, it must
+	// contain {{portal}}, which is replaced with the portal name.
+	Filename string
+	// Defaults to shared
+	Scope OutputScope
+}
+
+// Renders the extra outputs, with the portal definitions and the shared definition as input
+func (c Codegen) generateOutputs(outfolder string, sharedPD *portal.PortalDefinition) error {
+	for _, output := range c.outputs {
+		scope := output.Scope
+		if scope == "" {
+			scope = OutputScopeShared
+		}
+
+		switch scope {
+		case OutputScopeShared:
+			if strings.Contains(output.Filename, outputPortalPlaceholder) {
+				return fmt.Errorf(
+					"output %s is rendered once, so its file name can't contain %s",
+					output.Filename,
+					outputPortalPlaceholder,
+				)
+			}
+
+			err := c.generateOutput(outfolder, output, output.Filename, templates.OutputTemplateInput{
+				Portals: c.PortalDefinitions,
+				Shared:  sharedPD,
+			})
+			if err != nil {
+				return err
+			}
+		case OutputScopePortal:
+			if !strings.Contains(output.Filename, outputPortalPlaceholder) {
+				return fmt.Errorf(
+					"output %s is rendered per portal, so its file name must contain %s",
+					output.Filename,
+					outputPortalPlaceholder,
+				)
+			}
+
+			for i := range c.PortalDefinitions {
+				pd := &c.PortalDefinitions[i]
+				filename := strings.ReplaceAll(output.Filename, outputPortalPlaceholder, pd.PortalName)
+
+				err := c.generateOutput(outfolder, output, filename, templates.OutputTemplateInput{
+					Portal:  pd,
+					Portals: c.PortalDefinitions,
+					Shared:  sharedPD,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unknown scope %q for output %s", output.Scope, output.Filename)
+		}
+	}
+
+	return nil
+}
+
+func (c Codegen) generateOutput(
+	outfolder string,
+	output Output,
+	filename string,
+	input templates.OutputTemplateInput,
+) error {
+	c.logger.Printf("Generating %s from %s...\n", filename, output.Template)
+
+	code, err := templates.GenerateOutput(output.Template, input)
+	if err != nil {
+		return err
+	}
+
+	outPath := filepath.Join(outfolder, filename)
+	err = os.MkdirAll(filepath.Dir(outPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outPath, []byte(code), 0644)
+}
@@ -0,0 +1,225 @@
+package portal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/hs"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// namedOption is an enumeration option with the name of its enum member
+type namedOption struct {
+	Key    string
+	Option hs.Option
+}
+
+// Names the members of an enum after the option labels. Labels are transliterated before they are
+// sanitized, options whose label sanitizes to nothing are named after their value, reserved words
+// get a trailing underscore, and names that are taken fall back to the value or a number. Options
+// are named in display order, then by value, so the same options always get the same names.
+//
+// Returns the named options and a message for every option that isn't named after its label as is.
+func nameEnumOptions(enumName string, options []hs.Option) ([]namedOption, []string) {
+	sorted := append([]hs.Option{}, options...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].DisplayOrder != sorted[j].DisplayOrder {
+			return sorted[i].DisplayOrder < sorted[j].DisplayOrder
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+
+	named := []namedOption{}
+	warnings := []string{}
+	used := map[string]bool{}
+	for _, option := range sorted {
+		// The name the option used to get, which renames are reported against
+		plain := utils.PrependUnderscoreToEnum(utils.SanitizeLabel(option.Label))
+
+		key := enumMemberName(option.Label)
+		reason := ""
+		if key == "" {
+			key = enumMemberName(option.Value)
+			reason = "its label has no usable characters"
+		}
+		if key == "" {
+			key = "option"
+			reason = "neither its label nor its value has usable characters"
+		}
+
+		if used[key] {
+			taken := key
+			if valueKey := enumMemberName(option.Value); valueKey != "" && !used[valueKey] {
+				key = valueKey
+			} else {
+				for i := 2; used[key]; i++ {
+					key = fmt.Sprintf("%s_%d", taken, i)
+				}
+			}
+			reason = fmt.Sprintf("%s is already used", taken)
+		}
+		used[key] = true
+
+		if key != plain || reason != "" {
+			if reason == "" && utils.IsTypeScriptReservedWord(strings.TrimSuffix(key, "_")) {
+				reason = "its label is a reserved word"
+			} else if reason == "" {
+				reason = "its label was transliterated"
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"Enum %s: option %q (value %q) is named %s, as %s",
+				enumName,
+				option.Label,
+				option.Value,
+				key,
+				reason,
+			))
+		}
+
+		named = append(named, namedOption{Key: key, Option: option})
+	}
+
+	return named, warnings
+}
+
+// Builds an enum from its named options, escaping the values for the TypeScript string literals
+func newEnum(name string, namedOptions []namedOption) Enum {
+	enum := Enum{
+		Name:    name,
+		Values:  map[string]string{},
+		Options: []EnumOption{},
+	}
+	for _, named := range namedOptions {
+		enum.Values[named.Key] = strings.ReplaceAll(named.Option.Value, "\"", "\\\"")
+		enum.Options = append(enum.Options, EnumOption{
+			Key:         named.Key,
+			Value:       named.Option.Value,
+			Label:       named.Option.Label,
+			Description: named.Option.Description,
+			Order:       named.Option.DisplayOrder,
+			Hidden:      named.Option.Hidden,
+		})
+	}
+	return enum
+}
+
+// MergeEnums combines enums of the same name from several portals into one with the options of all
+// of them. Options are matched by value, where the metadata of the first enum with the value wins,
+// and the members are named again so every value gets a unique member.
+//
+// Returns the merged enum and a message for every option that isn't named after its label as is.
+func MergeEnums(name string, enums []Enum) (Enum, []string) {
+	options := []hs.Option{}
+	seen := map[string]bool{}
+	for _, enum := range enums {
+		for _, option := range enum.Options {
+			if seen[option.Value] {
+				continue
+			}
+			seen[option.Value] = true
+			options = append(options, hs.Option{
+				Hidden:       option.Hidden,
+				DisplayOrder: option.Order,
+				Description:  option.Description,
+				Label:        option.Label,
+				Value:        option.Value,
+			})
+		}
+	}
+
+	namedOptions, warnings := nameEnumOptions(name, options)
+	return newEnum(name, namedOptions), warnings
+}
+
+// Converts text into an enum member name, or an empty string if nothing of it is usable
+func enumMemberName(input string) string {
+	name := utils.SanitizeLabel(utils.Transliterate(input))
+	// SanitizeLabel keeps whitespace other than spaces, and returns _ for empty input
+	name = strings.Join(strings.Fields(name), "_")
+	if strings.Trim(name, "_") == "" {
+		return ""
+	}
+
+	name = utils.PrependUnderscoreToEnum(name)
+	if utils.IsTypeScriptReservedWord(name) {
+		name += "_"
+	}
+	return name
+}
+
+// Names the enum of every enumeration property with options, keyed by object.property. Enums are
+// named after the object and the property's internal name, with a number suffix when two properties
+// end up with the same name. When enums are shared, properties with the same options as an earlier
+// property use its enum. Objects and properties are visited by name, so the names don't depend on
+// the order HubSpot returns them in.
+//
+// Returns the enum names, and which properties create their enum rather than sharing one.
+func (pd *PortalDefinition) nameEnums() (map[string]string, map[string]bool) {
+	names := map[string]string{}
+	owners := map[string]bool{}
+	usedNames := map[string]bool{}
+	sharedEnums := map[string]string{}
+
+	schemas := append([]hs.Schema{}, pd.Schemas...)
+	sort.Slice(schemas, func(i, j int) bool {
+		return strings.ToLower(schemas[i].Name) < strings.ToLower(schemas[j].Name)
+	})
+
+	for _, schema := range schemas {
+		lowerSchemaName := strings.ToLower(schema.Name)
+
+		props := append([]hs.Property{}, schema.Properties...)
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		for _, prop := range props {
+			key := lowerSchemaName + "." + prop.Name
+			if prop.Type != "enumeration" || len(prop.Options) == 0 {
+				continue
+			}
+			if _, ok := pd.typeOverrides[key]; ok {
+				continue
+			}
+
+			signature := enumOptionsSignature(prop.Options)
+			if name, ok := sharedEnums[signature]; ok && pd.shareEnums {
+				names[key] = name
+				continue
+			}
+
+			base := pd.ObjectNameToType[lowerSchemaName].InterfaceName +
+				utils.ConvertSchemaNameToInterfaceName(prop.Name)
+			name := base + "Enum"
+			for i := 2; usedNames[name]; i++ {
+				name = fmt.Sprintf("%s%dEnum", base, i)
+			}
+			if name != base+"Enum" {
+				pd.logger.Printf(
+					"["+pd.PortalName+"] "+"Enum of %s is named %s, as %sEnum is already used\n",
+					key,
+					name,
+					base,
+				)
+			}
+
+			usedNames[name] = true
+			names[key] = name
+			owners[key] = true
+			if _, ok := sharedEnums[signature]; !ok {
+				sharedEnums[signature] = name
+			}
+		}
+	}
+
+	return names, owners
+}
+
+// Identifies a set of options by their values and labels, regardless of their order
+func enumOptionsSignature(options []hs.Option) string {
+	pairs := []string{}
+	for _, option := range options {
+		pairs = append(pairs, option.Value+"\x00"+option.Label)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\x01")
+}
@@ -6,11 +6,10 @@ var categoryMap = map[string]string{
 	"INTEGRATOR_DEFINED": "IntegratorDefined",
 }
 
-var typeConversionMap = map[string]string{
-	"date":               "string",
-	"datetime":           "string",
-	"bool":               "boolean",
-	"object_coordinates": "string",
-	"json":               "string",
-	"phone_number":       "string",
+// Object type IDs of the standard objects that support merging records
+var mergeableObjectTypeIDs = map[string]bool{
+	"0-1": true, // contact
+	"0-2": true, // company
+	"0-3": true, // deal
+	"0-5": true, // ticket
 }
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/killean-solvely/hsapi-gen/pkg/codegen/hs"
@@ -14,13 +15,16 @@ import (
 )
 
 type PortalDefinition struct {
-	PortalName       string                                       `json:"portal_id"`
-	Token            string                                       `json:"token"`
-	Schemas          []hs.Schema                                  `json:"schemas"`
-	AssociationTypes map[string]map[string]map[string]Association `json:"association_types"`
-	ObjectNameToType map[string]SchemaData                        `json:"object_name_to_type"`
-	filename         string
-	logger           *log.Logger
+	PortalName        string                                       `json:"portal_id"`
+	Token             string                                       `json:"token"`
+	Schemas           []hs.Schema                                  `json:"schemas"`
+	AssociationTypes  map[string]map[string]map[string]Association `json:"association_types"`
+	ObjectNameToType  map[string]SchemaData                        `json:"object_name_to_type"`
+	AssociatedObjects map[string][]string                          `json:"associated_objects"`
+	filename          string
+	logger            *log.Logger
+	typeOverrides     map[string]TypeOverride
+	shareEnums        bool
 
 	Enums     []Enum            `json:"enums"`
 	Objects   []Object          `json:"objects"`
@@ -33,19 +37,30 @@ func NewPortalDefinition(portalName, token string, logger *log.Logger) *PortalDe
 	//logger := log.New(logger.Panicf, "["+portalName+"] ", 0)
 
 	return &PortalDefinition{
-		PortalName:       portalName,
-		Token:            token,
-		Schemas:          []hs.Schema{},
-		AssociationTypes: map[string]map[string]map[string]Association{},
-		ObjectNameToType: map[string]SchemaData{},
-		filename:         filename,
-		logger:           logger,
-		Enums:            []Enum{},
-		Objects:          []Object{},
-		ObjectIDs:        objectIDs,
+		PortalName:        portalName,
+		Token:             token,
+		Schemas:           []hs.Schema{},
+		AssociationTypes:  map[string]map[string]map[string]Association{},
+		ObjectNameToType:  map[string]SchemaData{},
+		AssociatedObjects: map[string][]string{},
+		filename:          filename,
+		logger:            logger,
+		Enums:             []Enum{},
+		Objects:           []Object{},
+		ObjectIDs:         objectIDs,
 	}
 }
 
+// Sets the TypeScript types that replace the generated ones, keyed by object.property
+func (pd *PortalDefinition) SetTypeOverrides(overrides map[string]TypeOverride) {
+	pd.typeOverrides = overrides
+}
+
+// Sets whether enumeration properties with identical options share a single enum
+func (pd *PortalDefinition) SetShareEnums(enabled bool) {
+	pd.shareEnums = enabled
+}
+
 func (pd *PortalDefinition) LoadPortalDefinition() error {
 	// Check to see if the api file exists
 	_, err := os.Stat(pd.filename)
@@ -305,6 +320,7 @@ func (pd PortalDefinition) getAssociationTypes(
 
 func (pd *PortalDefinition) parseData() {
 	pd.parseSchemaData()
+	pd.parseAssociatedObjects()
 	pd.parseObjects()
 }
 
@@ -318,12 +334,49 @@ func (pd *PortalDefinition) parseSchemaData() {
 			),
 			Description: schema.Description,
 			ObjectID:    schema.ObjectTypeID,
+			Mergeable:   mergeableObjectTypeIDs[schema.ObjectTypeID],
 		}
 	}
 }
 
+// Builds the list of object types each object can be associated with from the schema definitions
+func (pd *PortalDefinition) parseAssociatedObjects() {
+	typeIDToName := map[string]string{}
+	for _, schema := range pd.Schemas {
+		typeIDToName[schema.ObjectTypeID] = strings.ToLower(schema.Name)
+	}
+
+	associated := map[string]map[string]bool{}
+	for _, schema := range pd.Schemas {
+		for _, assoc := range schema.Associations {
+			fromName, fromOk := typeIDToName[assoc.FromObjectTypeID]
+			toName, toOk := typeIDToName[assoc.ToObjectTypeID]
+			if !fromOk || !toOk {
+				continue
+			}
+
+			// HubSpot only lists the association on one of the objects, but it works both ways
+			for _, pair := range [][2]string{{fromName, toName}, {toName, fromName}} {
+				if _, ok := associated[pair[0]]; !ok {
+					associated[pair[0]] = map[string]bool{}
+				}
+				associated[pair[0]][pair[1]] = true
+			}
+		}
+	}
+
+	pd.AssociatedObjects = map[string][]string{}
+	for fromName, toNames := range associated {
+		for toName := range toNames {
+			pd.AssociatedObjects[fromName] = append(pd.AssociatedObjects[fromName], toName)
+		}
+		sort.Strings(pd.AssociatedObjects[fromName])
+	}
+}
+
 func (pd *PortalDefinition) parseObjects() {
-	createdEnums := map[string]bool{}
+	enumNames, enumOwners := pd.nameEnums()
+	usedOverrides := map[string]bool{}
 
 	for _, schema := range pd.Schemas {
 		lowerSchemaName := strings.ToLower(schema.Name)
@@ -334,61 +387,70 @@ func (pd *PortalDefinition) parseObjects() {
 
 		for _, prop := range schema.Properties {
 			propertyType := prop.Type
-			propertyLabel := prop.Label
 			propertyName := prop.Name
 
-			possibleEnumName := fmt.Sprintf(
-				"%s%s",
-				pd.ObjectNameToType[lowerSchemaName].InterfaceName,
-				utils.ConvertLabelToEnumName(propertyLabel),
-			)
-
-			isEnumeration := propertyType == "enumeration"
-			isEmptyEnumeration := len(prop.Options) == 0
+			// Overridden properties take the configured type instead of a generated enum
+			if override, ok := pd.typeOverrides[lowerSchemaName+"."+propertyName]; ok {
+				usedOverrides[lowerSchemaName+"."+propertyName] = true
+
+				property := Property{
+					Comment:        prop.Description,
+					Name:           propertyName,
+					Type:           override.Type,
+					HubspotType:    propertyType,
+					FieldType:      prop.FieldType,
+					Unique:         prop.HasUniqueValue,
+					TypeOverridden: true,
+				}
+				if override.Import != nil {
+					property.Imports = []TypeImport{*override.Import}
+				}
 
-			propType, ok := typeConversionMap[propertyType]
-			if !ok {
-				propType = propertyType
+				obj.Properties = append(obj.Properties, property)
+				continue
 			}
 
-			_, enumExists := createdEnums[possibleEnumName]
-			if isEnumeration && !enumExists && !(isEnumeration && isEmptyEnumeration) {
-				createdEnums[possibleEnumName] = true
+			// HubSpot sends every value as a string, so only enumerations get a narrower type
+			propType := "string"
+			if enumName, ok := enumNames[lowerSchemaName+"."+propertyName]; ok {
+				propType = enumName
+			}
 
-				propType = possibleEnumName + "Enum"
-				enumOptions := map[string]string{}
-				for _, option := range prop.Options {
-					sanitizedOptionLabel := utils.SanitizeLabel(option.Label)
-					if sanitizedOptionLabel == "" {
-						sanitizedOptionLabel = "_"
-					}
-					enumOptions[utils.PrependUnderscoreToEnum(sanitizedOptionLabel)] = strings.ReplaceAll(
-						option.Value,
-						"\"",
-						"\\\"",
-					)
+			// Properties sharing an enum only create it once
+			if enumOwners[lowerSchemaName+"."+propertyName] {
+				namedOptions, warnings := nameEnumOptions(propType, prop.Options)
+				for _, warning := range warnings {
+					pd.logger.Println("[" + pd.PortalName + "] " + warning)
 				}
 
-				pd.Enums = append(pd.Enums, Enum{
-					Name:   propType,
-					Values: enumOptions,
-				})
-			} else if isEmptyEnumeration {
-				propType = "string"
-			} else {
-				propType = "string"
+				pd.Enums = append(pd.Enums, newEnum(propType, namedOptions))
 			}
 
 			obj.Properties = append(obj.Properties, Property{
-				Comment: prop.Description,
-				Name:    propertyName,
-				Type:    propType,
+				Comment:     prop.Description,
+				Name:        propertyName,
+				Type:        propType,
+				HubspotType: propertyType,
+				FieldType:   prop.FieldType,
+				Unique:      prop.HasUniqueValue,
 			})
 		}
 
 		pd.Objects = append(pd.Objects, obj)
 		pd.ObjectIDs[pd.ObjectNameToType[lowerSchemaName].InterfaceName] = schema.ObjectTypeID
 	}
+
+	// Point out overrides that don't match anything, since they are most likely typos
+	unusedOverrides := []string{}
+	for key := range pd.typeOverrides {
+		if !usedOverrides[key] {
+			unusedOverrides = append(unusedOverrides, key)
+		}
+	}
+	sort.Strings(unusedOverrides)
+	for _, key := range unusedOverrides {
+		pd.logger.Printf("["+pd.PortalName+"] "+"Type override %s does not match any property\n", key)
+	}
 }
 
 func (pd PortalDefinition) saveAPIToFile() error {
@@ -4,6 +4,7 @@ type SchemaData struct {
 	InterfaceName string
 	Description   string
 	ObjectID      string
+	Mergeable     bool
 }
 
 type Association struct {
@@ -20,12 +21,48 @@ type AssociationConfig struct {
 type Enum struct {
 	Name   string
 	Values map[string]string
+	// HubSpot's metadata of every option kept in Values, in display order
+	Options []EnumOption
+}
+
+type EnumOption struct {
+	// Key of the option in Values
+	Key string
+	// Unescaped option value
+	Value       string
+	Label       string
+	Description string
+	Order       int
+	Hidden      bool
 }
 
 type Property struct {
-	Comment string
-	Name    string
-	Type    string
+	Comment     string
+	Name        string
+	Type        string
+	HubspotType string
+	// How HubSpot edits the property, where checkbox enumerations hold several values separated by ;
+	FieldType string
+	Unique    bool
+	// Whether Type is a TypeScript type expression from the config rather than string or an enum
+	TypeOverridden bool
+	// Imports the overridden type needs
+	Imports []TypeImport
+	// Portals that have the property, when the shared definition includes properties that only
+	// some portals have. Empty when every portal with the object has it.
+	Portals []string
+}
+
+// TypeOverride replaces the generated TypeScript type of a property
+type TypeOverride struct {
+	Type   string
+	Import *TypeImport
+}
+
+// TypeImport is a named import from a TypeScript module, relative to the generated files
+type TypeImport struct {
+	Name string `json:"name"`
+	From string `json:"from"`
 }
 
 type Object struct {
@@ -33,4 +70,7 @@ type Object struct {
 	InternalName string
 	Name         string
 	Properties   []Property
+	// Portals that have the object, when the shared definition includes objects that only some
+	// portals have. Empty when every portal has it.
+	Portals []string
 }
@@ -0,0 +1,363 @@
+package codegen
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Name of the lock file that keeps the field numbers stable across regenerations
+const protoLockFileName = "proto.lock.json"
+
+// Field numbers protobuf reserves for its own implementation
+const (
+	protoFirstReservedNumber = 19000
+	protoLastReservedNumber  = 19999
+)
+
+var invalidProtoNameChars = regexp.MustCompile(`[^_0-9A-Za-z]`)
+
+// Type names that are keywords or scalar types in proto files, which objects and enums must not reuse
+var reservedProtoNames = []string{
+	"bool",
+	"bytes",
+	"double",
+	"enum",
+	"float",
+	"int32",
+	"int64",
+	"message",
+	"string",
+}
+
+// protoLock records the numbers handed out to every message field and enum value
+type protoLock struct {
+	Messages map[string]*protoLockEntry `json:"messages"`
+	Enums    map[string]*protoLockEntry `json:"enums"`
+}
+
+// protoLockEntry is the numbering of a single message or enum
+type protoLockEntry struct {
+	Fields map[string]protoLockField `json:"fields"`
+	// Fields that no longer exist, kept so their numbers and names are never reused
+	Removed map[string]protoLockField `json:"removed,omitempty"`
+	// Numbers that were released when a field changed type
+	ReservedNumbers []int `json:"reservedNumbers,omitempty"`
+	NextNumber      int   `json:"nextNumber"`
+}
+
+type protoLockField struct {
+	Number int    `json:"number"`
+	Type   string `json:"type,omitempty"`
+}
+
+// Generates the protobuf definitions for the shared types, updating the field number lock file
+func (c Codegen) generateProtoFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
+	lockPath := path.Join(outfolder, protoLockFileName)
+	lock, err := loadProtoLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Println("Generating Protobuf Definitions...")
+	protoCode, err := c.renderer().GenerateProto(protoTemplateInput(c.protoPackage, sharedPD, lock))
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(outfolder, c.protoPackage+".proto"), []byte(protoCode), 0644)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(lockPath, append(data, '\n'), 0644)
+}
+
+// Reads the lock file from a previous run, or starts a new one if there is none
+func loadProtoLock(lockPath string) (*protoLock, error) {
+	lock := &protoLock{}
+
+	data, err := os.ReadFile(lockPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, lock)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if lock.Messages == nil {
+		lock.Messages = map[string]*protoLockEntry{}
+	}
+	if lock.Enums == nil {
+		lock.Enums = map[string]*protoLockEntry{}
+	}
+
+	return lock, nil
+}
+
+// Converts the shared portal definition into proto messages and enums, numbered from the lock
+func protoTemplateInput(
+	protoPackage string,
+	sharedPD *portal.PortalDefinition,
+	lock *protoLock,
+) templates.ProtoTemplateInput {
+	usedNames := map[string]bool{}
+	for _, name := range reservedProtoNames {
+		usedNames[name] = true
+	}
+
+	// Enums are sorted so the generated names are stable across runs
+	enums := append([]portal.Enum{}, sharedPD.Enums...)
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+
+	enumTypes := map[string]string{}
+	protoEnums := []templates.ProtoEnum{}
+	for _, enum := range enums {
+		name := uniqueIdentifier(protoName(utils.ToGoIdentifier(enum.Name)), usedNames)
+		enumTypes[enum.Name] = name
+
+		// Enum values share the scope of the package, so they are prefixed with the enum name
+		prefix := protoUpperSnakeCase(name) + "_"
+		unspecified := prefix + "UNSPECIFIED"
+
+		keys := []string{}
+		for key := range enum.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		valueNames := map[string]bool{unspecified: true}
+		current := map[string]string{}
+		for _, key := range keys {
+			current[uniqueIdentifier(prefix+strings.ToUpper(protoName(key)), valueNames)] = ""
+		}
+
+		entry := lockEntry(lock.Enums, name)
+		numbers := entry.assign(current)
+
+		protoEnum := templates.ProtoEnum{
+			Name: name,
+			// Proto3 enums must start with a zero value
+			Values:          []templates.ProtoEnumValue{{Name: unspecified, Number: 0}},
+			ReservedNumbers: entry.reservedNumbers(),
+			ReservedNames:   entry.reservedNames(),
+		}
+		for _, valueName := range sortedByNumber(numbers) {
+			protoEnum.Values = append(protoEnum.Values, templates.ProtoEnumValue{
+				Name:   valueName,
+				Number: numbers[valueName],
+			})
+		}
+
+		protoEnums = append(protoEnums, protoEnum)
+	}
+
+	objects := append([]portal.Object{}, sharedPD.Objects...)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].InternalName < objects[j].InternalName })
+
+	protoMessages := []templates.ProtoMessage{}
+	for _, obj := range objects {
+		name := uniqueIdentifier(protoName(utils.ToGoIdentifier(obj.Name)), usedNames)
+
+		props := append([]portal.Property{}, obj.Properties...)
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		// HubSpot sends every property value as a string
+		fieldNames := map[string]bool{}
+		current := map[string]string{}
+		fieldTypes := map[string]string{}
+		repeated := map[string]bool{}
+		comments := map[string]string{}
+		for _, prop := range props {
+			fieldType, ok := enumTypes[prop.Type]
+			if !ok {
+				fieldType = "string"
+			}
+
+			fieldName := uniqueIdentifier(protoName(prop.Name), fieldNames)
+			fieldTypes[fieldName] = fieldType
+			current[fieldName] = fieldType
+			// Checkbox enumerations hold several options. The label is part of the locked type, as
+			// a field that becomes repeated can't read the old data.
+			if ok && prop.FieldType == "checkbox" {
+				repeated[fieldName] = true
+				current[fieldName] = "repeated " + fieldType
+			}
+			comments[fieldName] = strings.Join(strings.Fields(prop.Comment), " ")
+		}
+
+		entry := lockEntry(lock.Messages, name)
+		numbers := entry.assign(current)
+
+		protoMessage := templates.ProtoMessage{
+			Name: name,
+			Comment: strings.Join(
+				strings.Fields(sharedPD.ObjectNameToType[obj.InternalName].Description),
+				" ",
+			),
+			ReservedNumbers: entry.reservedNumbers(),
+			ReservedNames:   entry.reservedNames(),
+		}
+		for _, fieldName := range sortedByNumber(numbers) {
+			protoMessage.Fields = append(protoMessage.Fields, templates.ProtoField{
+				Name:     fieldName,
+				Type:     fieldTypes[fieldName],
+				Repeated: repeated[fieldName],
+				Number:   numbers[fieldName],
+				Comment:  comments[fieldName],
+			})
+		}
+
+		protoMessages = append(protoMessages, protoMessage)
+	}
+
+	return templates.ProtoTemplateInput{
+		Package:  protoPackage,
+		Messages: protoMessages,
+		Enums:    protoEnums,
+	}
+}
+
+// Returns the lock entry for a message or enum, creating it the first time it is generated
+func lockEntry(entries map[string]*protoLockEntry, name string) *protoLockEntry {
+	entry, ok := entries[name]
+	if !ok {
+		entry = &protoLockEntry{}
+		entries[name] = entry
+	}
+	if entry.Fields == nil {
+		entry.Fields = map[string]protoLockField{}
+	}
+	if entry.Removed == nil {
+		entry.Removed = map[string]protoLockField{}
+	}
+	if entry.NextNumber < 1 {
+		entry.NextNumber = 1
+	}
+	return entry
+}
+
+// Numbers the current fields, keyed by name with their type as value. Fields that are gone
+// are moved to the removed fields, fields that come back with the same type get their old
+// number back, and fields that changed type get a new number so old data can't be misread.
+func (e *protoLockEntry) assign(current map[string]string) map[string]int {
+	for name, field := range e.Fields {
+		if _, ok := current[name]; !ok {
+			e.Removed[name] = field
+			delete(e.Fields, name)
+		}
+	}
+
+	names := []string{}
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	numbers := map[string]int{}
+	for _, name := range names {
+		fieldType := current[name]
+
+		field, ok := e.Fields[name]
+		if !ok {
+			field, ok = e.Removed[name]
+			delete(e.Removed, name)
+		}
+
+		if ok && field.Type != fieldType {
+			e.ReservedNumbers = append(e.ReservedNumbers, field.Number)
+			ok = false
+		}
+
+		if !ok {
+			field = protoLockField{Number: e.nextNumber(), Type: fieldType}
+		}
+
+		e.Fields[name] = field
+		numbers[name] = field.Number
+	}
+
+	sort.Ints(e.ReservedNumbers)
+	return numbers
+}
+
+// Hands out the next unused number, skipping the range protobuf reserves for itself
+func (e *protoLockEntry) nextNumber() int {
+	if e.NextNumber >= protoFirstReservedNumber && e.NextNumber <= protoLastReservedNumber {
+		e.NextNumber = protoLastReservedNumber + 1
+	}
+	number := e.NextNumber
+	e.NextNumber++
+	return number
+}
+
+// Returns the numbers that must not be reused, in order
+func (e *protoLockEntry) reservedNumbers() []int {
+	numbers := append([]int{}, e.ReservedNumbers...)
+	for _, field := range e.Removed {
+		numbers = append(numbers, field.Number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// Returns the names of the removed fields, in order
+func (e *protoLockEntry) reservedNames() []string {
+	names := []string{}
+	for name := range e.Removed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Orders the names by their number, so the generated file reads in the order fields were added
+func sortedByNumber(numbers map[string]int) []string {
+	names := []string{}
+	for name := range numbers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return numbers[names[i]] < numbers[names[j]] })
+	return names
+}
+
+// Replaces the characters proto files do not allow in names
+func protoName(input string) string {
+	name := invalidProtoNameChars.ReplaceAllString(input, "_")
+	// Names have to start with a letter
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "x" + name
+	}
+	return name
+}
+
+// Converts a camel case name into the upper snake case used for enum values
+func protoUpperSnakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
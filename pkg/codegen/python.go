@@ -0,0 +1,266 @@
+package codegen
+
+import (
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Names imported or defined by the generated modules, which objects and enums must not reuse
+var reservedPythonIdentifiers = []string{
+	"Any",
+	"Api",
+	"AssociableObjectApi",
+	"ASSOCIATION_BATCH_LIMIT",
+	"AssociationBatchFailure",
+	"AssociationBatchInput",
+	"AssociationBatchResult",
+	"AssociationSpec",
+	"BatchInputPublicAssociationMultiArchive",
+	"BatchInputPublicAssociationMultiPost",
+	"BatchInputSimplePublicObjectBatchInput",
+	"BatchInputSimplePublicObjectInputForCreate",
+	"BatchReadInputSimplePublicObjectId",
+	"Callable",
+	"cast",
+	"DefaultAssociableObjectApi",
+	"Dict",
+	"Enum",
+	"Generic",
+	"HubSpot",
+	"HubspotClient",
+	"List",
+	"Literal",
+	"MergeableObjectApi",
+	"OBJECT_TYPES",
+	"ObjectApi",
+	"ObjectKey",
+	"Optional",
+	"Portals",
+	"PropertyWithHistory",
+	"PublicAssociationMultiArchive",
+	"PublicAssociationMultiPost",
+	"PublicObjectId",
+	"SimplePublicObjectBatchInput",
+	"SimplePublicObjectId",
+	"SimplePublicObjectInput",
+	"SimplePublicObjectInputForCreate",
+	"T",
+	"TypedDict",
+	"TypeVar",
+}
+
+// Modules of the generated package, keywords, and lower case names the client module uses, which
+// the portal modules must not replace
+var reservedPythonModuleNames = []string{
+	"and", "as", "assert", "async", "await", "break", "cast", "class", "client", "continue",
+	"def", "del", "elif", "else", "enumerate", "except", "finally", "for", "from", "getattr",
+	"global", "if", "import", "in", "is", "lambda", "len", "list", "nonlocal", "not", "or",
+	"pass", "raise", "range", "return", "set", "shared", "str", "super", "try", "while", "with",
+	"yield",
+}
+
+// Generates the Python package for the portals
+func (c Codegen) generatePythonFiles(outfolder string, sharedPD *portal.PortalDefinition) error {
+	// Check to see if the output folder exists
+	if _, err := os.Stat(outfolder); os.IsNotExist(err) {
+		// Create the output folder
+		err := os.MkdirAll(outfolder, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	portalModules := map[string]string{}
+	for _, pd := range c.PortalDefinitions {
+		portalModules[pythonModuleName(pd.PortalName)] = pd.PortalName
+	}
+
+	sharedInput := pythonSharedTemplateInput(sharedPD)
+
+	// Generate the package init
+	initCode, err := c.renderer().GeneratePythonInit(templates.PythonInitTemplateInput{
+		Portals:      portalModules,
+		PythonClient: c.pythonClient,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(outfolder, "__init__.py"), []byte(initCode), 0644)
+	if err != nil {
+		return err
+	}
+
+	// Generate the client code
+	if c.pythonClient {
+		c.logger.Println("Generating Python Client Code...")
+		clientCode, err := c.renderer().GeneratePythonClient(templates.PythonClientTemplateInput{
+			Portals:           portalModules,
+			Objects:           sharedInput.Objects,
+			ObjectNameToType:  sharedPD.ObjectNameToType,
+			AssociationTypes:  sharedPD.AssociationTypes,
+			AssociatedObjects: sharedPD.AssociatedObjects,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(path.Join(outfolder, "client.py"), []byte(clientCode), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the code for the portals
+	c.logger.Println("Generating Python Portal Code...")
+	for i := range c.PortalDefinitions {
+		pd := &c.PortalDefinitions[i]
+		c.logger.Printf("Processing portal %s...\n", pd.PortalName)
+
+		objectMap := map[string]string{}
+		for _, obj := range pd.Objects {
+			objectMap[obj.InternalName] = obj.ID
+		}
+
+		portalCode, err := c.renderer().GeneratePythonPortal(templates.PythonPortalTemplateInput{
+			PortalName:       pd.PortalName,
+			Objects:          objectMap,
+			AssociationTypes: pd.AssociationTypes,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(
+			path.Join(outfolder, pythonModuleName(pd.PortalName)+".py"),
+			[]byte(portalCode),
+			0644,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the code for the shared types
+	c.logger.Println("Generating Python Shared Code...")
+	sharedCode, err := c.renderer().GeneratePythonShared(sharedInput)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path.Join(outfolder, "shared.py"), []byte(sharedCode), 0644)
+}
+
+// Converts the shared portal definition into Python identifiers and types
+func pythonSharedTemplateInput(sharedPD *portal.PortalDefinition) templates.PythonSharedTemplateInput {
+	usedNames := map[string]bool{}
+	for _, name := range reservedPythonIdentifiers {
+		usedNames[name] = true
+	}
+
+	// Enums are sorted so the generated names are stable across runs
+	enums := append([]portal.Enum{}, sharedPD.Enums...)
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+
+	enumTypes := map[string]string{}
+	pyEnums := []templates.PythonEnum{}
+	for _, enum := range enums {
+		// Exported Go identifiers are also valid Python class names
+		pyEnum := templates.PythonEnum{
+			Name: uniqueIdentifier(utils.ToGoIdentifier(enum.Name), usedNames),
+		}
+		enumTypes[enum.Name] = pyEnum.Name
+
+		keys := []string{}
+		for key := range enum.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		memberNames := map[string]bool{}
+		for _, key := range keys {
+			pyEnum.Values = append(pyEnum.Values, templates.PythonEnumValue{
+				Name: uniqueIdentifier(pythonEnumMemberName(key), memberNames),
+				// The values are escaped for TypeScript, so unescape them before quoting
+				Literal: strconv.Quote(strings.ReplaceAll(enum.Values[key], `\"`, `"`)),
+			})
+		}
+
+		pyEnums = append(pyEnums, pyEnum)
+	}
+
+	objects := append([]portal.Object{}, sharedPD.Objects...)
+	sort.Slice(objects, func(i, j int) bool { return objects[i].InternalName < objects[j].InternalName })
+
+	pyObjects := []templates.PythonObject{}
+	for _, obj := range objects {
+		pyObject := templates.PythonObject{
+			Name:         uniqueIdentifier(utils.ToGoIdentifier(obj.Name), usedNames),
+			InternalName: obj.InternalName,
+			Comment: strings.Join(
+				strings.Fields(sharedPD.ObjectNameToType[obj.InternalName].Description),
+				" ",
+			),
+		}
+
+		props := append([]portal.Property{}, obj.Properties...)
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		for _, prop := range props {
+			// HubSpot sends every property value as a string
+			fieldType, ok := enumTypes[prop.Type]
+			if !ok {
+				fieldType = "str"
+			}
+
+			pyObject.Fields = append(pyObject.Fields, templates.PythonField{
+				Key:     strconv.Quote(prop.Name),
+				Type:    fieldType,
+				Comment: strings.Join(strings.Fields(prop.Comment), " "),
+			})
+		}
+
+		pyObjects = append(pyObjects, pyObject)
+	}
+
+	return templates.PythonSharedTemplateInput{
+		Objects: pyObjects,
+		Enums:   pyEnums,
+	}
+}
+
+// Converts a sanitized enum label into an upper case enum member name
+func pythonEnumMemberName(key string) string {
+	name := strings.ToUpper(utils.PrependUnderscoreToEnum(key))
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// Converts the portal name into a module name that can be imported, suffixing names that would
+// replace a module of the package or a name the client uses
+func pythonModuleName(portalName string) string {
+	name := utils.SanitizeLabel(portalName)
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	// A single leading underscore keeps names like __init__ from being a module of the package, or
+	// a private name in the Portals enum
+	if strings.HasPrefix(name, "__") {
+		name = "_" + strings.TrimLeft(name, "_")
+	}
+	if slices.Contains(reservedPythonModuleNames, name) {
+		name += "_portal"
+	}
+	return name
+}
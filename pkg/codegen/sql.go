@@ -0,0 +1,349 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/hs"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+)
+
+// Suffix of the snapshot written next to every SQL export, which migrations are diffed from
+const sqlSnapshotSuffix = ".sql.json"
+
+// sqlSnapshot is the set of tables exported for a portal
+type sqlSnapshot struct {
+	Tables []sqlTable `json:"tables"`
+}
+
+type sqlTable struct {
+	Name       string      `json:"name"`
+	Columns    []sqlColumn `json:"columns"`
+	PrimaryKey []string    `json:"primaryKey"`
+	// Lines written above the CREATE TABLE statement, which are not compared by migrations
+	Comments []string `json:"comments,omitempty"`
+}
+
+type sqlColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Writes the Postgres tables mirroring every portal, along with a snapshot to diff later exports against
+func (c Codegen) exportSQL(outfolder string) error {
+	err := os.MkdirAll(outfolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.PortalDefinitions {
+		pd := &c.PortalDefinitions[i]
+		c.logger.Printf("Exporting SQL tables for portal %s...\n", pd.PortalName)
+
+		snapshot := sqlSnapshotForPortal(pd)
+
+		var b strings.Builder
+		b.WriteString("-- Code generated by hsapi-gen. DO NOT EDIT.\n")
+		for _, table := range snapshot.Tables {
+			b.WriteString("\n")
+			writeSQLCreateTable(&b, table)
+		}
+
+		err = os.WriteFile(path.Join(outfolder, pd.PortalName+".sql"), []byte(b.String()), 0644)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(
+			path.Join(outfolder, pd.PortalName+sqlSnapshotSuffix),
+			append(data, '\n'),
+			0644,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Writes the ALTER TABLE migration that turns the tables of one SQL snapshot into the tables of another
+func (c Codegen) ExportSQLMigration(outfolder, fromSnapshot, toSnapshot string) error {
+	from, err := loadSQLSnapshot(fromSnapshot)
+	if err != nil {
+		return err
+	}
+
+	to, err := loadSQLSnapshot(toSnapshot)
+	if err != nil {
+		return err
+	}
+
+	outfolder = path.Join(outfolder, string(ExportSQL))
+	err = os.MkdirAll(outfolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(path.Base(toSnapshot), sqlSnapshotSuffix) + ".migration.sql"
+	c.logger.Printf("Writing SQL migration %s...\n", name)
+
+	return os.WriteFile(path.Join(outfolder, name), []byte(sqlMigration(from, to)), 0644)
+}
+
+func loadSQLSnapshot(filename string) (*sqlSnapshot, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &sqlSnapshot{}
+	err = json.Unmarshal(data, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQL snapshot %s: %w", filename, err)
+	}
+
+	return snapshot, nil
+}
+
+// Builds a table per object, with a column per non archived property, and a join table per
+// pair of associated objects
+func sqlSnapshotForPortal(pd *portal.PortalDefinition) *sqlSnapshot {
+	snapshot := &sqlSnapshot{}
+
+	tableNames := map[string]bool{}
+	for _, schema := range pd.Schemas {
+		tableNames[strings.ToLower(schema.Name)] = true
+	}
+
+	for _, schema := range pd.Schemas {
+		table := sqlTable{
+			Name: strings.ToLower(schema.Name),
+			Columns: []sqlColumn{
+				{Name: "id", Type: "text"},
+				{Name: "archived", Type: "boolean"},
+			},
+			PrimaryKey: []string{"id"},
+		}
+		if schema.Description != "" {
+			table.Comments = append(table.Comments, strings.Join(strings.Fields(schema.Description), " "))
+		}
+
+		props := []hs.Property{}
+		for _, prop := range schema.Properties {
+			if !prop.Archived {
+				props = append(props, prop)
+			}
+		}
+		sort.Slice(props, func(i, j int) bool { return props[i].Name < props[j].Name })
+
+		columnNames := map[string]bool{"id": true, "archived": true}
+		for _, prop := range props {
+			table.Columns = append(table.Columns, sqlColumn{
+				Name: uniqueIdentifier(prop.Name, columnNames),
+				Type: sqlColumnType(prop.Type),
+			})
+		}
+
+		snapshot.Tables = append(snapshot.Tables, table)
+	}
+
+	// Associations are stored once per pair of objects, in the direction of the first name. Every
+	// pair of objects has an entry, so pairs without association types in either direction are skipped.
+	pairs := map[string][2]string{}
+	for fromName, toTypes := range pd.AssociationTypes {
+		for toName := range toTypes {
+			if !tableNames[fromName] || !tableNames[toName] {
+				continue
+			}
+			if len(pd.AssociationTypes[fromName][toName]) == 0 &&
+				len(pd.AssociationTypes[toName][fromName]) == 0 {
+				continue
+			}
+			pair := [2]string{fromName, toName}
+			if toName < fromName {
+				pair = [2]string{toName, fromName}
+			}
+			pairs[pair[0]+"_"+pair[1]] = pair
+		}
+	}
+
+	for _, pair := range pairs {
+		table := sqlTable{
+			Name: pair[0] + "_" + pair[1] + "_associations",
+			Columns: []sqlColumn{
+				{Name: "from_id", Type: "text"},
+				{Name: "to_id", Type: "text"},
+				{Name: "association_type_id", Type: "integer"},
+				{Name: "association_category", Type: "text"},
+			},
+			PrimaryKey: []string{"from_id", "to_id", "association_type_id"},
+		}
+
+		// List the association types of both directions so the IDs stored in the table can be looked up
+		directions := [][2]string{pair, {pair[1], pair[0]}}
+		if pair[0] == pair[1] {
+			directions = directions[:1]
+		}
+		for _, direction := range directions {
+			types := pd.AssociationTypes[direction[0]][direction[1]]
+			if len(types) == 0 {
+				continue
+			}
+
+			labels := []string{}
+			for label := range types {
+				labels = append(labels, label)
+			}
+			sort.Strings(labels)
+
+			table.Comments = append(
+				table.Comments,
+				fmt.Sprintf("Associations from %s to %s", direction[0], direction[1]),
+			)
+			for _, label := range labels {
+				table.Comments = append(
+					table.Comments,
+					fmt.Sprintf("%d: %s (%s)", types[label].ID, label, types[label].Category),
+				)
+			}
+		}
+
+		snapshot.Tables = append(snapshot.Tables, table)
+	}
+
+	sort.Slice(snapshot.Tables, func(i, j int) bool {
+		return snapshot.Tables[i].Name < snapshot.Tables[j].Name
+	})
+
+	return snapshot
+}
+
+// Maps a HubSpot property type to the Postgres column type holding its values
+func sqlColumnType(hubspotType string) string {
+	switch hubspotType {
+	case "number":
+		return "numeric"
+	case "bool":
+		return "boolean"
+	case "date":
+		return "date"
+	case "datetime":
+		return "timestamptz"
+	default:
+		return "text"
+	}
+}
+
+func writeSQLCreateTable(b *strings.Builder, table sqlTable) {
+	for _, comment := range table.Comments {
+		b.WriteString("-- " + comment + "\n")
+	}
+
+	b.WriteString("CREATE TABLE IF NOT EXISTS " + sqlIdentifier(table.Name) + " (\n")
+	for _, column := range table.Columns {
+		b.WriteString("  " + sqlIdentifier(column.Name) + " " + column.Type + ",\n")
+	}
+
+	keys := []string{}
+	for _, key := range table.PrimaryKey {
+		keys = append(keys, sqlIdentifier(key))
+	}
+	b.WriteString("  PRIMARY KEY (" + strings.Join(keys, ", ") + ")\n")
+	b.WriteString(");\n")
+}
+
+// Diffs the tables and columns of two snapshots. Removed tables and columns are dropped,
+// and columns whose type changed are cast to the new type.
+func sqlMigration(from, to *sqlSnapshot) string {
+	fromTables := map[string]sqlTable{}
+	for _, table := range from.Tables {
+		fromTables[table.Name] = table
+	}
+	toTables := map[string]bool{}
+	for _, table := range to.Tables {
+		toTables[table.Name] = true
+	}
+
+	statements := []string{}
+	for _, table := range to.Tables {
+		fromTable, ok := fromTables[table.Name]
+		if !ok {
+			var b strings.Builder
+			writeSQLCreateTable(&b, table)
+			statements = append(statements, b.String())
+			continue
+		}
+
+		fromColumns := map[string]string{}
+		for _, column := range fromTable.Columns {
+			fromColumns[column.Name] = column.Type
+		}
+		toColumns := map[string]bool{}
+
+		alterations := []string{}
+		for _, column := range table.Columns {
+			toColumns[column.Name] = true
+
+			fromType, ok := fromColumns[column.Name]
+			switch {
+			case !ok:
+				alterations = append(alterations, fmt.Sprintf(
+					"ADD COLUMN %s %s",
+					sqlIdentifier(column.Name),
+					column.Type,
+				))
+			case fromType != column.Type:
+				alterations = append(alterations, fmt.Sprintf(
+					"ALTER COLUMN %s TYPE %s USING %s::%s",
+					sqlIdentifier(column.Name),
+					column.Type,
+					sqlIdentifier(column.Name),
+					column.Type,
+				))
+			}
+		}
+		for _, column := range fromTable.Columns {
+			if !toColumns[column.Name] {
+				alterations = append(alterations, "DROP COLUMN "+sqlIdentifier(column.Name))
+			}
+		}
+
+		if len(alterations) > 0 {
+			statements = append(statements, fmt.Sprintf(
+				"ALTER TABLE %s\n  %s;\n",
+				sqlIdentifier(table.Name),
+				strings.Join(alterations, ",\n  "),
+			))
+		}
+	}
+	for _, table := range from.Tables {
+		if !toTables[table.Name] {
+			statements = append(statements, "DROP TABLE IF EXISTS "+sqlIdentifier(table.Name)+";\n")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("-- Code generated by hsapi-gen. DO NOT EDIT.\n\n")
+	b.WriteString("BEGIN;\n")
+	for _, statement := range statements {
+		b.WriteString("\n" + statement)
+	}
+	b.WriteString("\nCOMMIT;\n")
+
+	return b.String()
+}
+
+// Quotes a table or column name, so reserved words and upper case names can be used
+func sqlIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
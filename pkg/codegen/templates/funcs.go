@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Funcs returns the functions available to every template, including overridden ones:
+//
+//   - lower, upper: change the case of the whole string
+//   - firstUpper: upper cases the first letter, like utils.ToFirstUpper
+//   - pascalCase: turns a snake case schema name into an interface name, like utils.ConvertSchemaNameToInterfaceName
+//   - enumName: turns a label into an enum name, like utils.ConvertLabelToEnumName
+//   - snakeCase: turns a label into a lower snake case name, like utils.SanitizeLabel
+//   - goIdentifier: turns any name into an exported Go identifier, like utils.ToGoIdentifier
+//   - sortedKeys: returns the keys of a map with string keys in order, for ranging in a stable order
+//   - sortStrings: returns a sorted copy of a list of strings
+//   - join: joins a list of strings with a separator, like strings.Join
+//   - jsdoc: collapses whitespace and escapes the text so it can't end a JSDoc comment
+//   - jsString: quotes the text as a JavaScript string literal
+//   - hasKey: reports whether a map has the key, for example `hasKey $.AssociationTypes $objectName`
+//   - tsKey: returns a TypeScript property key, quoting names that aren't identifiers
+//   - md: escapes text for use in a Markdown table cell
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"lower":        strings.ToLower,
+		"upper":        strings.ToUpper,
+		"firstUpper":   utils.ToFirstUpper,
+		"pascalCase":   utils.ConvertSchemaNameToInterfaceName,
+		"enumName":     utils.ConvertLabelToEnumName,
+		"snakeCase":    utils.SanitizeLabel,
+		"goIdentifier": utils.ToGoIdentifier,
+		"sortedKeys":   sortedKeys,
+		"sortStrings":  sortStrings,
+		"join":         strings.Join,
+		"jsdoc":        jsdoc,
+		"jsString":     jsString,
+		"hasKey":       hasKey,
+		"tsKey":        utils.ToTypeScriptPropertyKey,
+		"md":           markdownCell,
+	}
+}
+
+func sortedKeys(m any) ([]string, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("sortedKeys: expected a map with string keys, got %T", m)
+	}
+
+	keys := []string{}
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+func sortStrings(input []string) []string {
+	sorted := append([]string{}, input...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func jsdoc(input string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(input), " "), "*/", `*\/`)
+}
+
+// JSON strings are valid JavaScript string literals, unlike Go's quoted strings with escapes
+// like \a or \U0001F600
+func jsString(input string) string {
+	data, _ := json.Marshal(input)
+	return string(data)
+}
+
+func hasKey(m any, key string) (bool, error) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return false, fmt.Errorf("hasKey: expected a map with string keys, got %T", m)
+	}
+
+	return v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).IsValid(), nil
+}
@@ -3,25 +3,63 @@ package templates
 import (
 	"bytes"
 	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
 )
 
-//go:embed static/*.tstpl
+//go:embed static/*.tstpl static/*.gotpl static/*.pytpl static/*.graphqltpl static/*.prototpl static/*.mdtpl static/*.htmltpl
 var templates embed.FS
 
-func createTemplate(name, t string) *template.Template {
-	return template.Must(template.New(name).Parse(t))
+// Renderer renders the built-in templates. Templates in Dir override the embedded templates with
+// the same file name, for example a client.tstpl with a different base client. Templates that are
+// not in the folder keep using the embedded version.
+type Renderer struct {
+	Dir string
 }
 
-func generateCode[T any](input T, templatePath string) (string, error) {
-	f, err := templates.ReadFile(templatePath)
+// NewRenderer returns a renderer that uses the templates in dir over the embedded ones, or only
+// the embedded ones when dir is empty
+func NewRenderer(dir string) Renderer {
+	return Renderer{Dir: dir}
+}
+
+func createTemplate(name, t string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs()).Parse(t)
+}
+
+// Reads a template from the templates folder if it overrides it, or the embedded templates otherwise
+func (r Renderer) readTemplate(templatePath string) ([]byte, error) {
+	if r.Dir != "" {
+		f, err := os.ReadFile(filepath.Join(r.Dir, path.Base(templatePath)))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return templates.ReadFile(templatePath)
+}
+
+func generateCode[T any](r Renderer, input T, templatePath string) (string, error) {
+	f, err := r.readTemplate(templatePath)
 	if err != nil {
 		return "", err
 	}
 
-	t := createTemplate("temp", string(f))
+	t, err := createTemplate(path.Base(templatePath), string(f))
+	if err != nil {
+		return "", err
+	}
 
 	var buf bytes.Buffer
 	err = t.Execute(&buf, input)
@@ -33,31 +71,389 @@ func generateCode[T any](input T, templatePath string) (string, error) {
 }
 
 type HubspotClientTemplateInput struct {
-	PortalNames      map[string]string
-	ObjectNameToType map[string]portal.SchemaData
-	AssociationTypes map[string]map[string]map[string]portal.Association
+	PortalNames       map[string]string
+	ObjectNameToType  map[string]portal.SchemaData
+	AssociationTypes  map[string]map[string]map[string]portal.Association
+	AssociatedObjects map[string][]string
+	Zod               bool
+	// Clients typed with the objects of each portal, by portal name, which replace the shared client
+	PortalClients map[string]HubspotClientTemplateInput
 }
 
-func GenerateClient(input HubspotClientTemplateInput) (string, error) {
-	return generateCode(input, "static/client.tstpl")
+func (r Renderer) GenerateClient(input HubspotClientTemplateInput) (string, error) {
+	return generateCode(r, input, "static/client.tstpl")
 }
 
 type PortalTemplateInput struct {
+	PortalName        string
+	AssociationTypes  map[string]map[string]map[string]portal.Association
+	AssociatedObjects map[string][]string
+	Objects           map[string]string
+	// Whether the portal gets its own object types, declared from ObjectTypes
+	PerPortal   bool
+	ObjectTypes []PortalObjectType
+}
+
+// PortalObjectType is a shared object narrowed down to the properties a portal has
+type PortalObjectType struct {
+	InternalName     string
+	Interface        string
+	Properties       []string
+	UniqueProperties []string
+}
+
+func (r Renderer) GeneratePortal(input PortalTemplateInput) (string, error) {
+	return generateCode(r, input, "static/portal.tstpl")
+}
+
+type SharedTemplateInput struct {
+	AssociationTypes  map[string]map[string]map[string]portal.Association
+	AssociatedObjects map[string][]string
+	Enums             []portal.Enum
+	Objects           []portal.Object
+	Imports           []TypeScriptImport
+	ConstEnums        bool
+}
+
+// TypeScriptImport is a type only import of the names, which are joined with commas
+type TypeScriptImport struct {
+	From  string
+	Names string
+}
+
+func (r Renderer) GenerateShared(input SharedTemplateInput) (string, error) {
+	return generateCode(r, input, "static/shared.tstpl")
+}
+
+type SchemasTemplateInput struct {
+	Objects []portal.Object
+}
+
+func (r Renderer) GenerateSchemas(input SchemasTemplateInput) (string, error) {
+	return generateCode(r, input, "static/schemas.tstpl")
+}
+
+type GoEnumValue struct {
+	Name    string
+	Literal string
+}
+
+type GoEnum struct {
+	Name   string
+	Values []GoEnumValue
+}
+
+type GoField struct {
+	Name     string
+	JSONName string
+	Type     string
+	Comment  string
+}
+
+type GoObject struct {
+	Name         string
+	InternalName string
+	KeyName      string
+	Comment      string
+	Fields       []GoField
+}
+
+type GoAssociationKey struct {
+	Name  string
+	Value string
+}
+
+type GoSharedTemplateInput struct {
+	Package         string
+	Objects         []GoObject
+	Enums           []GoEnum
+	AssociationKeys []GoAssociationKey
+}
+
+func (r Renderer) GenerateGoShared(input GoSharedTemplateInput) (string, error) {
+	return generateCode(r, input, "static/shared.gotpl")
+}
+
+type GoPortalTemplateInput struct {
+	Package          string
 	PortalName       string
-	AssociationTypes map[string]map[string]map[string]portal.Association
+	PortalIdent      string
 	Objects          map[string]string
+	AssociationTypes map[string]map[string]map[string]portal.Association
 }
 
-func GeneratePortal(input PortalTemplateInput) (string, error) {
-	return generateCode(input, "static/portal.tstpl")
+func (r Renderer) GenerateGoPortal(input GoPortalTemplateInput) (string, error) {
+	return generateCode(r, input, "static/portal.gotpl")
 }
 
-type SharedTemplateInput struct {
+type GoClientTemplateInput struct {
+	Package string
+	Portals map[string]string
+}
+
+func (r Renderer) GenerateGoClient(input GoClientTemplateInput) (string, error) {
+	return generateCode(r, input, "static/client.gotpl")
+}
+
+type PythonEnumValue struct {
+	Name    string
+	Literal string
+}
+
+type PythonEnum struct {
+	Name   string
+	Values []PythonEnumValue
+}
+
+type PythonField struct {
+	Key     string
+	Type    string
+	Comment string
+}
+
+type PythonObject struct {
+	Name         string
+	InternalName string
+	Comment      string
+	Fields       []PythonField
+}
+
+type PythonSharedTemplateInput struct {
+	Objects []PythonObject
+	Enums   []PythonEnum
+}
+
+func (r Renderer) GeneratePythonShared(input PythonSharedTemplateInput) (string, error) {
+	return generateCode(r, input, "static/shared.pytpl")
+}
+
+type PythonPortalTemplateInput struct {
+	PortalName       string
+	Objects          map[string]string
 	AssociationTypes map[string]map[string]map[string]portal.Association
-	Enums            []portal.Enum
-	Objects          []portal.Object
 }
 
-func GenerateShared(input SharedTemplateInput) (string, error) {
-	return generateCode(input, "static/shared.tstpl")
+func (r Renderer) GeneratePythonPortal(input PythonPortalTemplateInput) (string, error) {
+	return generateCode(r, input, "static/portal.pytpl")
+}
+
+type PythonClientTemplateInput struct {
+	Portals           map[string]string
+	Objects           []PythonObject
+	ObjectNameToType  map[string]portal.SchemaData
+	AssociationTypes  map[string]map[string]map[string]portal.Association
+	AssociatedObjects map[string][]string
+}
+
+func (r Renderer) GeneratePythonClient(input PythonClientTemplateInput) (string, error) {
+	return generateCode(r, input, "static/client.pytpl")
+}
+
+type PythonInitTemplateInput struct {
+	Portals      map[string]string
+	PythonClient bool
+}
+
+func (r Renderer) GeneratePythonInit(input PythonInitTemplateInput) (string, error) {
+	return generateCode(r, input, "static/init.pytpl")
+}
+
+type GraphQLField struct {
+	Name        string
+	Type        string
+	Description string
+	// Name of the HubSpot property when it is not a valid GraphQL name
+	Property string
+	// Type name of the associated object for association fields
+	AssociatedType string
+}
+
+type GraphQLObject struct {
+	Name         string
+	InternalName string
+	QueryName    string
+	Description  string
+	Fields       []GraphQLField
+}
+
+type GraphQLEnumValue struct {
+	Name string
+	// Quoted HubSpot value of the option
+	Value string
+}
+
+type GraphQLEnum struct {
+	Name   string
+	Values []GraphQLEnumValue
+}
+
+type GraphQLTemplateInput struct {
+	Objects []GraphQLObject
+	Enums   []GraphQLEnum
+}
+
+func (r Renderer) GenerateGraphQLSchema(input GraphQLTemplateInput) (string, error) {
+	return generateCode(r, input, "static/schema.graphqltpl")
+}
+
+func (r Renderer) GenerateGraphQLResolvers(input GraphQLTemplateInput) (string, error) {
+	return generateCode(r, input, "static/resolvers.tstpl")
+}
+
+type ProtoField struct {
+	Name string
+	Type string
+	// Whether the field holds several values, like the options of a checkbox enumeration
+	Repeated bool
+	Number   int
+	Comment  string
+}
+
+type ProtoMessage struct {
+	Name            string
+	Comment         string
+	Fields          []ProtoField
+	ReservedNumbers []int
+	ReservedNames   []string
+}
+
+type ProtoEnumValue struct {
+	Name   string
+	Number int
+}
+
+type ProtoEnum struct {
+	Name            string
+	Values          []ProtoEnumValue
+	ReservedNumbers []int
+	ReservedNames   []string
+}
+
+type ProtoTemplateInput struct {
+	Package  string
+	Messages []ProtoMessage
+	Enums    []ProtoEnum
+}
+
+func (r Renderer) GenerateProto(input ProtoTemplateInput) (string, error) {
+	return generateCode(r, input, "static/shared.prototpl")
+}
+
+// DocsCell is the value of an attribute in one column, split into lines
+type DocsCell struct {
+	Lines []string
+}
+
+type DocsAttribute struct {
+	Name  string
+	Cells []DocsCell
+}
+
+type DocsProperty struct {
+	Name string
+	// Either the portal names, or a single column when the property is the same in every portal
+	Columns    []string
+	Attributes []DocsAttribute
+}
+
+type DocsAssociation struct {
+	ToObject string
+	// HubSpot's label of the association type, or the key when it has none
+	Label string
+	// Key of the association type in the generated code
+	Key string
+	// One cell per portal
+	Cells []DocsCell
+}
+
+type DocsObject struct {
+	Name         string
+	Label        string
+	Description  string
+	FileName     string
+	Portals      []DocsCell
+	Properties   []DocsProperty
+	Associations []DocsAssociation
+}
+
+type DocsTemplateInput struct {
+	Portals []string
+	Objects []DocsObject
+}
+
+type DocsObjectTemplateInput struct {
+	Portals []string
+	Object  DocsObject
+}
+
+func (r Renderer) GenerateDocsIndex(input DocsTemplateInput) (string, error) {
+	return generateCode(r, input, "static/index.mdtpl")
+}
+
+func (r Renderer) GenerateDocsObject(input DocsObjectTemplateInput) (string, error) {
+	return generateCode(r, input, "static/object.mdtpl")
+}
+
+func (r Renderer) GenerateDocsHTML(input DocsTemplateInput) (string, error) {
+	f, err := r.readTemplate("static/docs.htmltpl")
+	if err != nil {
+		return "", err
+	}
+
+	// html/template escapes the values for us
+	t, err := htmltemplate.New("docs.htmltpl").Funcs(htmltemplate.FuncMap(Funcs())).Parse(string(f))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, input)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+var markdownCellReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"<", "&lt;",
+	">", "&gt;",
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+)
+
+// Escapes text so it can't break out of a Markdown table cell
+func markdownCell(input string) string {
+	return markdownCellReplacer.Replace(strings.Join(strings.Fields(input), " "))
+}
+
+type OutputTemplateInput struct {
+	// The portal being rendered, or nil when the output is rendered once
+	Portal  *portal.PortalDefinition
+	Portals []portal.PortalDefinition
+	Shared  *portal.PortalDefinition
+}
+
+// GenerateOutput renders a user defined template from a file outside of the embedded templates
+func GenerateOutput(templatePath string, input OutputTemplateInput) (string, error) {
+	f, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := createTemplate(filepath.Base(templatePath), string(f))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, input)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
 }
@@ -0,0 +1,33 @@
+package codegen
+
+import (
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+)
+
+// Transformer changes the portal models after they are loaded and before any code is generated,
+// for example to rename objects, add synthetic properties or override types.
+//
+// The shared definition has already been built from the portals when Transform is called, so a
+// change that should reach the shared types has to be made to it as well as to the portals.
+type Transformer interface {
+	Transform(portals []portal.PortalDefinition, shared *portal.PortalDefinition) error
+}
+
+// TransformerFunc lets an ordinary function be used as a Transformer
+type TransformerFunc func(portals []portal.PortalDefinition, shared *portal.PortalDefinition) error
+
+func (f TransformerFunc) Transform(portals []portal.PortalDefinition, shared *portal.PortalDefinition) error {
+	return f(portals, shared)
+}
+
+// Runs the transformers in the order they were added, stopping at the first error
+func (c Codegen) runTransformers(sharedPD *portal.PortalDefinition) error {
+	for _, transformer := range c.transformers {
+		err := transformer.Transform(c.PortalDefinitions, sharedPD)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/utils"
+)
+
+// Names declared by the generated shared.ts, which objects and enums must not reuse
+var reservedTypeScriptDeclarations = []string{
+	"AssocConfigType",
+	"AssociatedObjectTypes",
+	"AssociationsConfigType",
+	"EnumOptionMetadata",
+	"EnumOptions",
+	"ObjectKeys",
+	"ObjectTypes",
+	"ObjectUniqueProperties",
+	"labelFor",
+}
+
+// Checks every name the TypeScript templates use as a bare identifier, and reports all of the
+// invalid ones at once so they can be fixed in a single pass. Property names are left out, as
+// they are quoted when they need to be.
+func (c Codegen) validateTypeScriptIdentifiers(sharedPD *portal.PortalDefinition) error {
+	problems := []string{}
+
+	declared := map[string]string{}
+	for _, name := range reservedTypeScriptDeclarations {
+		declared[name] = "a generated declaration"
+	}
+
+	// Declarations also need to stay clear of reserved words and each other
+	declare := func(name, what string) {
+		switch {
+		case !utils.IsTypeScriptIdentifier(name):
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid identifier", what, name))
+		case utils.IsTypeScriptReservedWord(name):
+			problems = append(problems, fmt.Sprintf("%s %q is a reserved word", what, name))
+		case declared[name] != "":
+			problems = append(problems, fmt.Sprintf("%s %q is already used by %s", what, name, declared[name]))
+		default:
+			declared[name] = what
+		}
+	}
+	key := func(name, what string) {
+		if !utils.IsTypeScriptIdentifier(name) {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid identifier", what, name))
+		}
+	}
+
+	for _, pd := range c.PortalDefinitions {
+		declare(pd.PortalName+"AssociationsConfig", "portal "+pd.PortalName+" declaration")
+		key(pd.PortalName, "portal name")
+
+		// Every portal also gets its own types, and a client in a namespace named after it
+		if c.mergeStrategy == MergePerPortal {
+			declare(pd.PortalName, "client namespace of portal "+pd.PortalName)
+			for _, suffix := range []string{
+				"ObjectKeys",
+				"ObjectTypes",
+				"ObjectUniqueProperties",
+				"AssociationsConfigType",
+				"AssociatedObjectTypes",
+			} {
+				declare(pd.PortalName+suffix, "portal "+pd.PortalName+" declaration")
+			}
+		}
+	}
+
+	for _, enum := range sharedPD.Enums {
+		declare(enum.Name, "enum name")
+		declare(enum.Name+"Options", "enum options of "+enum.Name)
+		for member := range enum.Values {
+			key(member, "member of enum "+enum.Name)
+		}
+	}
+
+	imported := map[string]bool{}
+	for _, obj := range sharedPD.Objects {
+		declare(obj.Name, "interface name of object "+obj.InternalName)
+		key(obj.InternalName, "object name")
+		for _, prop := range obj.Properties {
+			for _, imp := range prop.Imports {
+				// Several properties can use the same imported type
+				if !imported[imp.Name] {
+					imported[imp.Name] = true
+					declare(imp.Name, "type import of "+obj.InternalName+"."+prop.Name)
+				}
+			}
+		}
+	}
+
+	// The association keys of every portal end up in its configuration
+	for _, pd := range append([]portal.PortalDefinition{*sharedPD}, c.PortalDefinitions...) {
+		for fromName, toTypes := range pd.AssociationTypes {
+			key(fromName, "object name")
+			for toName, labels := range toTypes {
+				key(toName, "object name")
+				for label, assoc := range labels {
+					key(label, "association key")
+					if assoc.SanitizedLabel != "" {
+						key(assoc.SanitizedLabel, "association label key")
+					}
+				}
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	// The same name can be reported by several portals
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, problem := range problems {
+		if !seen[problem] {
+			seen[problem] = true
+			unique = append(unique, problem)
+		}
+	}
+	sort.Strings(unique)
+
+	return fmt.Errorf(
+		"cannot generate TypeScript because of invalid identifiers:\n  - %s",
+		strings.Join(unique, "\n  - "),
+	)
+}
@@ -0,0 +1,149 @@
+package codegen
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/portal"
+	"github.com/killean-solvely/hsapi-gen/pkg/codegen/templates"
+)
+
+// TypeOverride replaces the generated TypeScript type of a property
+type TypeOverride struct {
+	// The property as object.property, using the internal names
+	Property string
+	// TypeScript type expression used instead of the generated type
+	Type string
+	// Optional import the type needs
+	Import *portal.TypeImport
+	// Only apply the override to this portal. Applies to every portal when empty.
+	Portal string
+}
+
+// Picks the overrides of a portal, where overrides for the portal win over global ones
+func (c Codegen) typeOverridesForPortal(portalName string) map[string]portal.TypeOverride {
+	overrides := map[string]portal.TypeOverride{}
+
+	for _, override := range c.typeOverrides {
+		if override.Portal == "" {
+			overrides[strings.ToLower(override.Property)] = portal.TypeOverride{
+				Type:   override.Type,
+				Import: override.Import,
+			}
+		}
+	}
+	for _, override := range c.typeOverrides {
+		if override.Portal == portalName {
+			overrides[strings.ToLower(override.Property)] = portal.TypeOverride{
+				Type:   override.Type,
+				Import: override.Import,
+			}
+		}
+	}
+
+	return overrides
+}
+
+// Combines a property that is overridden in at least one portal, so the shared type accepts
+// the type of every portal
+func mergeOverriddenProperty(sharedProp, prop portal.Property) portal.Property {
+	if sharedProp.Type != prop.Type {
+		sharedProp.Type = "(" + sharedProp.Type + ") | (" + prop.Type + ")"
+	}
+	sharedProp.TypeOverridden = true
+
+	for _, imp := range prop.Imports {
+		found := false
+		for _, sharedImp := range sharedProp.Imports {
+			if sharedImp == imp {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sharedProp.Imports = append(sharedProp.Imports, imp)
+		}
+	}
+
+	return sharedProp
+}
+
+// Groups the imports of the overridden types by module
+func typeScriptImports(objects []portal.Object) []templates.TypeScriptImport {
+	namesByModule := map[string]map[string]bool{}
+	for _, obj := range objects {
+		for _, prop := range obj.Properties {
+			for _, imp := range prop.Imports {
+				if namesByModule[imp.From] == nil {
+					namesByModule[imp.From] = map[string]bool{}
+				}
+				namesByModule[imp.From][imp.Name] = true
+			}
+		}
+	}
+
+	modules := []string{}
+	for module := range namesByModule {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	imports := []templates.TypeScriptImport{}
+	for _, module := range modules {
+		names := []string{}
+		for name := range namesByModule[module] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		imports = append(imports, templates.TypeScriptImport{
+			From:  module,
+			Names: strings.Join(names, ", "),
+		})
+	}
+
+	return imports
+}
+
+// Adds the enums that the shared properties still use but that aren't in every portal, which
+// happens when an override for one portal stops it from creating the enum of a property. The merged
+// type of the property then still includes the enum of the other portals.
+func keepReferencedEnums(
+	enums []portal.Enum,
+	objects []portal.Object,
+	portals []portal.PortalDefinition,
+) []portal.Enum {
+	kept := map[string]bool{}
+	for _, enum := range enums {
+		kept[enum.Name] = true
+	}
+
+	sharedProps := map[string]bool{}
+	for _, obj := range objects {
+		for _, prop := range obj.Properties {
+			sharedProps[obj.InternalName+"."+prop.Name] = true
+		}
+	}
+
+	for _, portalDef := range portals {
+		portalEnums := map[string]portal.Enum{}
+		for _, enum := range portalDef.Enums {
+			portalEnums[enum.Name] = enum
+		}
+
+		for _, obj := range portalDef.Objects {
+			for _, prop := range obj.Properties {
+				if !sharedProps[obj.InternalName+"."+prop.Name] || prop.TypeOverridden {
+					continue
+				}
+				enum, ok := portalEnums[prop.Type]
+				if ok && !kept[enum.Name] {
+					kept[enum.Name] = true
+					enums = append(enums, enum)
+				}
+			}
+		}
+	}
+
+	return enums
+}
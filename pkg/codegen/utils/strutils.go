@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // SanitizeLabel removes special characters, replaces spaces with underscores, and converts to lowercase.
@@ -60,3 +61,32 @@ func ToFirstUpper(word string) string {
 	}
 	return ""
 }
+
+// Words that are kept fully uppercase in Go identifiers.
+var goInitialisms = map[string]string{
+	"api": "API",
+	"id":  "ID",
+	"url": "URL",
+}
+
+// ToGoIdentifier splits the input on any non alphanumeric character, capitalizes each word, and joins them into an exported Go identifier.
+func ToGoIdentifier(input string) string {
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if initialism, ok := goInitialisms[strings.ToLower(word)]; ok {
+			words[i] = initialism
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(first)) + word[size:]
+	}
+
+	ident := strings.Join(words, "")
+	first, _ := utf8.DecodeRuneInString(ident)
+	if !unicode.IsUpper(first) {
+		ident = "X" + ident
+	}
+	return ident
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// Words that can't be used as the name of a TypeScript declaration.
+var typeScriptReservedWords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true, "else": true,
+	"enum": true, "export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "implements": true, "import": true, "in": true,
+	"instanceof": true, "interface": true, "let": true, "new": true, "null": true,
+	"package": true, "private": true, "protected": true, "public": true, "return": true,
+	"static": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true,
+}
+
+// IsTypeScriptReservedWord reports whether the name can't be used to name a TypeScript declaration.
+func IsTypeScriptReservedWord(name string) bool {
+	return typeScriptReservedWords[name]
+}
+
+// IsTypeScriptIdentifier reports whether the name follows the TypeScript identifier grammar, so it can be
+// used as a bare property key. Reserved words are identifiers too, but can't name declarations.
+func IsTypeScriptIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || r == '$' || unicode.IsLetter(r):
+		case i > 0 && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ToTypeScriptPropertyKey returns the name as is when it can be a bare property key, or quoted otherwise.
+func ToTypeScriptPropertyKey(name string) string {
+	if IsTypeScriptIdentifier(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}